@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// ActorKey is the gRPC metadata key and HTTP header
+// the actor is read from at the transport boundary
+var ActorKey = "x-user-id"
+
+// ActorFromGRPC returns the actor from the incoming gRPC metadata of
+// the context, e.g. to set it with WithActor in an interceptor
+func ActorFromGRPC(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(ActorKey)
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+	return values[0], true
+}
+
+// ActorFromHTTP returns the actor from the headers of the request,
+// e.g. to set it with WithActor in a middleware
+func ActorFromHTTP(r *http.Request) (string, bool) {
+	actor := r.Header.Get(ActorKey)
+	if actor == "" {
+		return "", false
+	}
+	return actor, true
+}
@@ -0,0 +1,64 @@
+package audit_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/odpf/salt/audit"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestActorFromGRPC(t *testing.T) {
+	t.Run("should return actor from incoming metadata", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("X-User-Id", "user@example.com"))
+
+		actor, ok := audit.ActorFromGRPC(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "user@example.com", actor)
+	})
+
+	t.Run("should return false if actor is missing", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "123"))
+
+		actor, ok := audit.ActorFromGRPC(ctx)
+		assert.False(t, ok)
+		assert.Empty(t, actor)
+	})
+
+	t.Run("should return false if there is no incoming metadata", func(t *testing.T) {
+		actor, ok := audit.ActorFromGRPC(context.Background())
+		assert.False(t, ok)
+		assert.Empty(t, actor)
+	})
+
+	t.Run("should read actor from configured key", func(t *testing.T) {
+		defer func(key string) { audit.ActorKey = key }(audit.ActorKey)
+		audit.ActorKey = "x-email"
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-email", "user@example.com"))
+
+		actor, ok := audit.ActorFromGRPC(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "user@example.com", actor)
+	})
+}
+
+func TestActorFromHTTP(t *testing.T) {
+	t.Run("should return actor from request headers", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-User-Id", "user@example.com")
+
+		actor, ok := audit.ActorFromHTTP(r)
+		assert.True(t, ok)
+		assert.Equal(t, "user@example.com", actor)
+	})
+
+	t.Run("should return false if actor is missing", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+
+		actor, ok := audit.ActorFromHTTP(r)
+		assert.False(t, ok)
+		assert.Empty(t, actor)
+	})
+}
@@ -18,26 +18,34 @@ var (
 type actorContextKey struct{}
 type metadataContextKey struct{}
 
+// WithActor returns a context carrying the actor picked up by Service.Log
 func WithActor(ctx context.Context, actor string) context.Context {
 	return context.WithValue(ctx, actorContextKey{}, actor)
 }
 
+// WithMetadata returns a context carrying the metadata picked up by Service.Log,
+// merged on top of any metadata already present in the parent context
 func WithMetadata(ctx context.Context, md map[string]interface{}) (context.Context, error) {
 	existingMetadata := ctx.Value(metadataContextKey{})
 	if existingMetadata == nil {
 		return context.WithValue(ctx, metadataContextKey{}, md), nil
 	}
 
-	// append new metadata
 	mapMd, ok := existingMetadata.(map[string]interface{})
 	if !ok {
 		return nil, ErrInvalidMetadata
 	}
+
+	// copy existing metadata so the parent context is left untouched
+	newMd := make(map[string]interface{}, len(mapMd)+len(md))
+	for k, v := range mapMd {
+		newMd[k] = v
+	}
 	for k, v := range md {
-		mapMd[k] = v
+		newMd[k] = v
 	}
 
-	return context.WithValue(ctx, metadataContextKey{}, mapMd), nil
+	return context.WithValue(ctx, metadataContextKey{}, newMd), nil
 }
 
 type repository interface {
@@ -68,6 +76,25 @@ func WithActorExtractor(fn func(context.Context) (string, error)) AuditOption {
 	}
 }
 
+// Hook is run on every log before it is inserted, it can enrich the log in
+// place and returns keep as false to silently skip inserting the log
+type Hook func(*Log) (keep bool, err error)
+
+// WithHooks appends hooks run in the given order before inserting a log
+func WithHooks(hooks ...Hook) AuditOption {
+	return func(s *Service) {
+		s.hooks = append(s.hooks, hooks...)
+	}
+}
+
+// WithDataRegistry sets the schema and version of the logged
+// data if its type is registered, unless a hook sets them
+func WithDataRegistry(r *DataRegistry) AuditOption {
+	return func(s *Service) {
+		s.dataRegistry = r
+	}
+}
+
 func defaultActorExtractor(ctx context.Context) (string, error) {
 	if actor, ok := ctx.Value(actorContextKey{}).(string); ok {
 		return actor, nil
@@ -76,14 +103,18 @@ func defaultActorExtractor(ctx context.Context) (string, error) {
 }
 
 type Service struct {
-	repository     repository
-	actorExtractor func(context.Context) (string, error)
-	withMetadata   func(context.Context) (context.Context, error)
+	repository       repository
+	actorExtractor   func(context.Context) (string, error)
+	traceIDExtractor func(context.Context) string
+	withMetadata     func(context.Context) (context.Context, error)
+	dataRegistry     *DataRegistry
+	hooks            []Hook
 }
 
 func New(opts ...AuditOption) *Service {
 	svc := &Service{
-		actorExtractor: defaultActorExtractor,
+		actorExtractor:   defaultActorExtractor,
+		traceIDExtractor: defaultTraceIDExtractor,
 	}
 	for _, o := range opts {
 		o(svc)
@@ -92,6 +123,8 @@ func New(opts ...AuditOption) *Service {
 	return svc
 }
 
+// Log records an audit log for the action, populating the timestamp along
+// with the actor, metadata and trace id found in the context
 func (s *Service) Log(ctx context.Context, action string, data interface{}) error {
 	if s.withMetadata != nil {
 		var err error
@@ -105,10 +138,18 @@ func (s *Service) Log(ctx context.Context, action string, data interface{}) erro
 		Action:    action,
 		Data:      data,
 	}
+	if s.dataRegistry != nil {
+		l.DataSchema, l.DataVersion, _ = s.dataRegistry.SchemaOf(data)
+	}
 
 	if md, ok := ctx.Value(metadataContextKey{}).(map[string]interface{}); ok {
 		l.Metadata = md
 	}
+	if s.traceIDExtractor != nil {
+		if traceID := s.traceIDExtractor(ctx); traceID != "" {
+			l.Metadata = withTraceID(l.Metadata, traceID)
+		}
+	}
 
 	if s.actorExtractor != nil {
 		actor, err := s.actorExtractor(ctx)
@@ -118,5 +159,15 @@ func (s *Service) Log(ctx context.Context, action string, data interface{}) erro
 		l.Actor = actor
 	}
 
+	for _, hook := range s.hooks {
+		keep, err := hook(l)
+		if err != nil {
+			return fmt.Errorf("running hook: %w", err)
+		}
+		if !keep {
+			return nil
+		}
+	}
+
 	return s.repository.Insert(ctx, l)
 }
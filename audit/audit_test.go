@@ -10,6 +10,7 @@ import (
 	"github.com/odpf/salt/audit/mocks"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/metadata"
 )
 
 type AuditTestSuite struct {
@@ -153,6 +154,233 @@ func (s *AuditTestSuite) TestLog() {
 		})
 	})
 
+	s.Run("context", func() {
+		s.Run("should populate actor, metadata and timestamp from context", func() {
+			s.setupTest()
+			s.service = audit.New(audit.WithRepository(s.mockRepository))
+
+			s.mockRepository.On("Insert", mock.Anything, &audit.Log{
+				Timestamp: s.now,
+				Action:    "action",
+				Actor:     "user@example.com",
+				Data:      "data",
+				Metadata:  map[string]interface{}{"app_name": "guardian_test"},
+			}).Return(nil).Once()
+
+			ctx := audit.WithActor(context.Background(), "user@example.com")
+			ctx, err := audit.WithMetadata(ctx, map[string]interface{}{"app_name": "guardian_test"})
+			s.Require().NoError(err)
+
+			err = s.service.Log(ctx, "action", "data")
+			s.NoError(err)
+			s.mockRepository.AssertExpectations(s.T())
+		})
+
+		s.Run("should not modify metadata of the parent context", func() {
+			s.setupTest()
+			s.service = audit.New(audit.WithRepository(s.mockRepository))
+
+			parentCtx, err := audit.WithMetadata(context.Background(), map[string]interface{}{"parent": "foo"})
+			s.Require().NoError(err)
+			childCtx, err := audit.WithMetadata(parentCtx, map[string]interface{}{"child": "bar"})
+			s.Require().NoError(err)
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Equal(map[string]interface{}{"parent": "foo"}, l.Metadata)
+			}).Return(nil).Once()
+			s.NoError(s.service.Log(parentCtx, "", nil))
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Equal(map[string]interface{}{"parent": "foo", "child": "bar"}, l.Metadata)
+			}).Return(nil).Once()
+			s.NoError(s.service.Log(childCtx, "", nil))
+		})
+	})
+
+	s.Run("trace id", func() {
+		s.Run("should add trace id of the context to metadata", func() {
+			s.setupTest()
+			s.service = audit.New(audit.WithRepository(s.mockRepository))
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Equal(map[string]interface{}{"app_name": "guardian_test", "trace_id": "test-trace-id"}, l.Metadata)
+			}).Return(nil).Once()
+
+			ctx, err := audit.WithMetadata(context.Background(), map[string]interface{}{"app_name": "guardian_test"})
+			s.Require().NoError(err)
+			s.NoError(s.service.Log(audit.WithTraceID(ctx, "test-trace-id"), "action", nil))
+		})
+
+		s.Run("should add trace id of the incoming traceparent", func() {
+			s.setupTest()
+			s.service = audit.New(audit.WithRepository(s.mockRepository))
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Equal(map[string]interface{}{"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"}, l.Metadata)
+			}).Return(nil).Once()
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			))
+			s.NoError(s.service.Log(ctx, "action", nil))
+		})
+
+		s.Run("should use trace id extractor if option given", func() {
+			s.setupTest()
+			s.service = audit.New(
+				audit.WithRepository(s.mockRepository),
+				audit.WithTraceIDExtractor(func(context.Context) string { return "span-trace-id" }),
+			)
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Equal(map[string]interface{}{"trace_id": "span-trace-id"}, l.Metadata)
+			}).Return(nil).Once()
+
+			s.NoError(s.service.Log(context.Background(), "action", nil))
+		})
+
+		s.Run("should keep trace id set in metadata", func() {
+			s.setupTest()
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Equal("test-trace-id", l.Metadata.(map[string]interface{})["trace_id"])
+			}).Return(nil).Once()
+
+			s.NoError(s.service.Log(audit.WithTraceID(context.Background(), "other-trace-id"), "action", nil))
+		})
+
+		s.Run("should omit trace id without trace context", func() {
+			s.setupTest()
+			s.service = audit.New(audit.WithRepository(s.mockRepository))
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Nil(l.Metadata)
+			}).Return(nil).Once()
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", "invalid"))
+			s.NoError(s.service.Log(ctx, "action", nil))
+		})
+	})
+
+	s.Run("hooks", func() {
+		s.Run("should insert log enriched by hooks", func() {
+			s.setupTest()
+			s.service = audit.New(
+				audit.WithRepository(s.mockRepository),
+				audit.WithHooks(
+					func(l *audit.Log) (bool, error) {
+						l.Metadata = map[string]interface{}{"correlation_id": "test-correlation-id"}
+						return true, nil
+					},
+					func(l *audit.Log) (bool, error) {
+						l.Metadata.(map[string]interface{})["app_name"] = "guardian_test"
+						return true, nil
+					},
+				),
+			)
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Equal(map[string]interface{}{
+					"correlation_id": "test-correlation-id",
+					"app_name":       "guardian_test",
+				}, l.Metadata)
+			}).Return(nil).Once()
+
+			err := s.service.Log(context.Background(), "action", nil)
+			s.NoError(err)
+			s.mockRepository.AssertExpectations(s.T())
+		})
+
+		s.Run("should skip insert if a hook drops the log", func() {
+			s.setupTest()
+			nextHookCalled := false
+			s.service = audit.New(
+				audit.WithRepository(s.mockRepository),
+				audit.WithHooks(
+					func(l *audit.Log) (bool, error) {
+						return l.Action != "noisy", nil
+					},
+					func(l *audit.Log) (bool, error) {
+						nextHookCalled = true
+						return true, nil
+					},
+				),
+			)
+
+			err := s.service.Log(context.Background(), "noisy", nil)
+			s.NoError(err)
+			s.False(nextHookCalled)
+			s.mockRepository.AssertNotCalled(s.T(), "Insert", mock.Anything, mock.Anything)
+		})
+
+		s.Run("should return error if a hook returns error", func() {
+			s.setupTest()
+			expectedError := errors.New("test error")
+			s.service = audit.New(
+				audit.WithRepository(s.mockRepository),
+				audit.WithHooks(func(l *audit.Log) (bool, error) {
+					return false, expectedError
+				}),
+			)
+
+			err := s.service.Log(context.Background(), "action", nil)
+			s.ErrorIs(err, expectedError)
+			s.mockRepository.AssertNotCalled(s.T(), "Insert", mock.Anything, mock.Anything)
+		})
+	})
+
+	s.Run("data registry", func() {
+		type appeal struct {
+			ID string `json:"id"`
+		}
+
+		s.Run("should set schema of registered data", func() {
+			s.setupTest()
+			registry := audit.NewDataRegistry()
+			registry.Register("appeal", 2, appeal{})
+			s.service = audit.New(
+				audit.WithRepository(s.mockRepository),
+				audit.WithDataRegistry(registry),
+			)
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Equal("appeal", l.DataSchema)
+				s.Equal(2, l.DataVersion)
+			}).Return(nil).Once()
+
+			err := s.service.Log(context.Background(), "appeal.create", &appeal{ID: "1"})
+			s.NoError(err)
+			s.mockRepository.AssertExpectations(s.T())
+		})
+
+		s.Run("should leave schema of unregistered data empty", func() {
+			s.setupTest()
+			s.service = audit.New(
+				audit.WithRepository(s.mockRepository),
+				audit.WithDataRegistry(audit.NewDataRegistry()),
+			)
+
+			s.mockRepository.On("Insert", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				l := args.Get(1).(*audit.Log)
+				s.Empty(l.DataSchema)
+				s.Zero(l.DataVersion)
+			}).Return(nil).Once()
+
+			err := s.service.Log(context.Background(), "action", map[string]interface{}{"foo": "bar"})
+			s.NoError(err)
+			s.mockRepository.AssertExpectations(s.T())
+		})
+	})
+
 	s.Run("should return error if repository.Insert fails", func() {
 		s.setupTest()
 
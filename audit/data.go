@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrUnknownDataSchema = errors.New("unknown data schema")
+
+type dataSchema struct {
+	name    string
+	version int
+}
+
+// DataRegistry maps the types of Log.Data to a schema name and version,
+// so logs are stored with the schema of their data and the data can be
+// decoded back into the registered type when listing logs
+type DataRegistry struct {
+	types   map[dataSchema]reflect.Type
+	schemas map[reflect.Type]dataSchema
+}
+
+func NewDataRegistry() *DataRegistry {
+	return &DataRegistry{
+		types:   map[dataSchema]reflect.Type{},
+		schemas: map[reflect.Type]dataSchema{},
+	}
+}
+
+// Register registers the type of v as the given version of the schema,
+// e.g. Register("appeal", 2, AppealV2{}). Data decoded from the schema
+// has the same type as v. It panics if the schema version or the type
+// is already registered.
+func (r *DataRegistry) Register(schema string, version int, v interface{}) {
+	t := reflect.TypeOf(v)
+	s := dataSchema{schema, version}
+	if _, ok := r.types[s]; ok {
+		panic(fmt.Sprintf("audit: data schema %s version %d registered twice", schema, version))
+	}
+	if _, ok := r.schemas[t]; ok {
+		panic(fmt.Sprintf("audit: data type %s registered twice", t))
+	}
+	r.types[s] = t
+	r.schemas[t] = s
+}
+
+// SchemaOf returns the schema and version registered for the type
+// of v, a pointer to a registered type has the same schema
+func (r *DataRegistry) SchemaOf(v interface{}) (schema string, version int, ok bool) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return "", 0, false
+	}
+	s, ok := r.schemas[t]
+	if !ok && t.Kind() == reflect.Ptr {
+		s, ok = r.schemas[t.Elem()]
+	}
+	return s.name, s.version, ok
+}
+
+// Decode unmarshals the JSON data into a new value of the type registered
+// for the schema version, it returns ErrUnknownDataSchema if none is
+func (r *DataRegistry) Decode(schema string, version int, data []byte) (interface{}, error) {
+	t, ok := r.types[dataSchema{schema, version}]
+	if !ok {
+		return nil, fmt.Errorf("decoding data of schema %s version %d: %w", schema, version, ErrUnknownDataSchema)
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return nil, fmt.Errorf("decoding data of schema %s version %d: %w", schema, version, err)
+	}
+	return v.Elem().Interface(), nil
+}
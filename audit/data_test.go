@@ -0,0 +1,78 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/odpf/salt/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+type appealV1 struct {
+	ID string `json:"id"`
+}
+
+type appealV2 struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+func TestDataRegistry(t *testing.T) {
+	newRegistry := func() *audit.DataRegistry {
+		r := audit.NewDataRegistry()
+		r.Register("appeal", 1, appealV1{})
+		r.Register("appeal", 2, appealV2{})
+		return r
+	}
+
+	t.Run("should return schema of registered type and its pointer", func(t *testing.T) {
+		r := newRegistry()
+
+		schema, version, ok := r.SchemaOf(appealV2{})
+		assert.True(t, ok)
+		assert.Equal(t, "appeal", schema)
+		assert.Equal(t, 2, version)
+
+		schema, version, ok = r.SchemaOf(&appealV1{})
+		assert.True(t, ok)
+		assert.Equal(t, "appeal", schema)
+		assert.Equal(t, 1, version)
+	})
+
+	t.Run("should not return schema of unregistered type", func(t *testing.T) {
+		_, _, ok := newRegistry().SchemaOf(map[string]interface{}{})
+		assert.False(t, ok)
+
+		_, _, ok = newRegistry().SchemaOf(nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("should decode data into type of schema version", func(t *testing.T) {
+		r := newRegistry()
+
+		data, err := r.Decode("appeal", 1, []byte(`{"id":"1","reason":"access"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, appealV1{ID: "1"}, data)
+
+		data, err = r.Decode("appeal", 2, []byte(`{"id":"1","reason":"access"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, appealV2{ID: "1", Reason: "access"}, data)
+	})
+
+	t.Run("should return error if schema version is unknown", func(t *testing.T) {
+		_, err := newRegistry().Decode("appeal", 3, []byte(`{}`))
+		assert.ErrorIs(t, err, audit.ErrUnknownDataSchema)
+		assert.EqualError(t, err, "decoding data of schema appeal version 3: unknown data schema")
+	})
+
+	t.Run("should return error if data does not match type", func(t *testing.T) {
+		_, err := newRegistry().Decode("appeal", 1, []byte(`{"id":1}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("should panic if registered twice", func(t *testing.T) {
+		r := newRegistry()
+
+		assert.Panics(t, func() { r.Register("appeal", 1, struct{}{}) })
+		assert.Panics(t, func() { r.Register("appeal", 3, appealV1{}) })
+	})
+}
@@ -1,11 +1,78 @@
 package audit
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrMissingAction   = errors.New("action is required")
+	ErrMissingActor    = errors.New("actor is required")
+	ErrInvalidSeverity = errors.New("severity must be one of info, notice, warning or critical")
+)
+
+// Severity tells how sensitive the audited event is,
+// e.g. to alert on critical events
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityNotice   Severity = "notice"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
 
 type Log struct {
 	Timestamp time.Time
 	Action    string
 	Actor     string
+	Severity  Severity
 	Data      interface{}
 	Metadata  interface{}
+
+	// DataSchema and DataVersion identify the type of Data,
+	// e.g. to decode it with a DataRegistry when listing logs
+	DataSchema  string
+	DataVersion int
+}
+
+// Validate returns an error if the action or the actor of the log
+// is missing or the severity is invalid, and sets the timestamp to
+// the current time and the severity to info if not set
+func (l *Log) Validate() error {
+	if l.Action == "" {
+		return fmt.Errorf("invalid audit log: %w", ErrMissingAction)
+	}
+	if l.Actor == "" {
+		return fmt.Errorf("invalid audit log: %w", ErrMissingActor)
+	}
+
+	switch l.Severity {
+	case "":
+		l.Severity = SeverityInfo
+	case SeverityInfo, SeverityNotice, SeverityWarning, SeverityCritical:
+	default:
+		return fmt.Errorf("invalid audit log: %w", ErrInvalidSeverity)
+	}
+
+	if l.Timestamp.IsZero() {
+		l.Timestamp = TimeNow()
+	}
+	return nil
+}
+
+// Filter narrows down the logs returned when listing audit logs,
+// zero valued fields are ignored
+type Filter struct {
+	Actor    string
+	Action   string
+	Severity Severity
+
+	// StartTime is inclusive and EndTime is exclusive
+	StartTime time.Time
+	EndTime   time.Time
+
+	Limit  int
+	Offset int
 }
@@ -0,0 +1,69 @@
+package audit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogValidate(t *testing.T) {
+	timestamp := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should return error if action is missing", func(t *testing.T) {
+		l := &audit.Log{Timestamp: timestamp, Actor: "user@example.com"}
+
+		err := l.Validate()
+		assert.ErrorIs(t, err, audit.ErrMissingAction)
+		assert.EqualError(t, err, "invalid audit log: action is required")
+	})
+
+	t.Run("should return error if actor is missing", func(t *testing.T) {
+		l := &audit.Log{Timestamp: timestamp, Action: "action"}
+
+		err := l.Validate()
+		assert.ErrorIs(t, err, audit.ErrMissingActor)
+		assert.EqualError(t, err, "invalid audit log: actor is required")
+	})
+
+	t.Run("should return error if severity is invalid", func(t *testing.T) {
+		l := &audit.Log{Timestamp: timestamp, Action: "action", Actor: "user@example.com", Severity: "urgent"}
+
+		err := l.Validate()
+		assert.ErrorIs(t, err, audit.ErrInvalidSeverity)
+		assert.EqualError(t, err, "invalid audit log: severity must be one of info, notice, warning or critical")
+	})
+
+	t.Run("should set severity to info if missing", func(t *testing.T) {
+		l := &audit.Log{Timestamp: timestamp, Action: "action", Actor: "user@example.com"}
+
+		assert.NoError(t, l.Validate())
+		assert.Equal(t, audit.SeverityInfo, l.Severity)
+	})
+
+	t.Run("should keep severity if set", func(t *testing.T) {
+		l := &audit.Log{Timestamp: timestamp, Action: "action", Actor: "user@example.com", Severity: audit.SeverityNotice}
+
+		assert.NoError(t, l.Validate())
+		assert.Equal(t, audit.SeverityNotice, l.Severity)
+	})
+
+	t.Run("should set timestamp if missing", func(t *testing.T) {
+		now := time.Now()
+		audit.TimeNow = func() time.Time { return now }
+		defer func() { audit.TimeNow = time.Now }()
+
+		l := &audit.Log{Action: "action", Actor: "user@example.com"}
+
+		assert.NoError(t, l.Validate())
+		assert.Equal(t, now, l.Timestamp)
+	})
+
+	t.Run("should keep timestamp if set", func(t *testing.T) {
+		l := &audit.Log{Timestamp: timestamp, Action: "action", Actor: "user@example.com"}
+
+		assert.NoError(t, l.Validate())
+		assert.Equal(t, timestamp, l.Timestamp)
+	})
+}
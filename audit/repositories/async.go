@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/odpf/salt/audit"
+)
+
+const (
+	defaultAsyncBufferSize    = 100
+	defaultAsyncFlushInterval = 5 * time.Second
+
+	// asyncRetainFactor times the buffer size is the number of logs
+	// kept for the next flush while the writes fail
+	asyncRetainFactor = 10
+)
+
+var ErrRepositoryClosed = errors.New("repository is closed")
+
+type batchRepository interface {
+	Init(context.Context) error
+	BatchInsert(context.Context, []*audit.Log) error
+}
+
+type AsyncOption func(*AsyncRepository)
+
+// WithBufferSize sets the number of buffered logs that triggers a flush,
+// sizes less than 1 are ignored
+func WithBufferSize(size int) AsyncOption {
+	return func(r *AsyncRepository) {
+		if size > 0 {
+			r.bufferSize = size
+		}
+	}
+}
+
+// WithFlushInterval sets the interval at which buffered logs are flushed
+// regardless of the buffer size, intervals less than or equal to zero
+// are ignored
+func WithFlushInterval(d time.Duration) AsyncOption {
+	return func(r *AsyncRepository) {
+		if d > 0 {
+			r.flushInterval = d
+		}
+	}
+}
+
+// WithFlushErrorHandler sets the handler called when a periodic flush fails,
+// errors are written to stderr by default
+func WithFlushErrorHandler(fn func(error)) AsyncOption {
+	return func(r *AsyncRepository) {
+		r.errorHandler = fn
+	}
+}
+
+// AsyncRepository buffers inserted logs and writes them to the underlying
+// repository in batches, either when the buffer is full or periodically.
+// Logs of a failed write are put back in front of the buffer to be
+// written by the next flush, up to ten times the buffer size, the
+// oldest logs are dropped beyond it. Close must be called on shutdown
+// to flush the remaining logs.
+type AsyncRepository struct {
+	repository    batchRepository
+	bufferSize    int
+	flushInterval time.Duration
+	errorHandler  func(error)
+
+	mu     sync.Mutex
+	buffer []*audit.Log
+	closed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewAsyncRepository(r batchRepository, opts ...AsyncOption) *AsyncRepository {
+	repo := &AsyncRepository{
+		repository:    r,
+		bufferSize:    defaultAsyncBufferSize,
+		flushInterval: defaultAsyncFlushInterval,
+		errorHandler:  printFlushError,
+		done:          make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(repo)
+	}
+	repo.buffer = make([]*audit.Log, 0, repo.bufferSize)
+
+	repo.wg.Add(1)
+	go repo.run()
+
+	return repo
+}
+
+func (r *AsyncRepository) Init(ctx context.Context) error {
+	return r.repository.Init(ctx)
+}
+
+// Insert validates the log, adds it to the buffer and flushes the
+// buffer synchronously once it reaches the configured size. An
+// invalid log is returned its error and not buffered, so it does not
+// fail the flush of the others. The error of the flush is returned,
+// the log is kept in the buffer then.
+func (r *AsyncRepository) Insert(ctx context.Context, l *audit.Log) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrRepositoryClosed
+	}
+	r.buffer = append(r.buffer, l)
+	var logs []*audit.Log
+	if len(r.buffer) >= r.bufferSize {
+		logs = r.swapBuffer()
+	}
+	r.mu.Unlock()
+
+	return r.write(ctx, logs)
+}
+
+// Flush writes all buffered logs to the underlying repository,
+// they are kept in the buffer if the write fails
+func (r *AsyncRepository) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	logs := r.swapBuffer()
+	r.mu.Unlock()
+
+	return r.write(ctx, logs)
+}
+
+// Close stops the periodic flush and writes the remaining buffered logs,
+// Flush can be called again if it fails
+func (r *AsyncRepository) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	close(r.done)
+	r.wg.Wait()
+
+	return r.Flush(ctx)
+}
+
+func (r *AsyncRepository) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			if err := r.Flush(context.Background()); err != nil {
+				r.errorHandler(err)
+			}
+		}
+	}
+}
+
+// swapBuffer must be called with r.mu held
+func (r *AsyncRepository) swapBuffer() []*audit.Log {
+	if len(r.buffer) == 0 {
+		return nil
+	}
+	logs := r.buffer
+	r.buffer = make([]*audit.Log, 0, r.bufferSize)
+	return logs
+}
+
+func (r *AsyncRepository) write(ctx context.Context, logs []*audit.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	if err := r.repository.BatchInsert(ctx, logs); err != nil {
+		if dropped := r.requeue(logs); dropped > 0 {
+			return fmt.Errorf("flushing %d audit logs, dropped %d: %w", len(logs), dropped, err)
+		}
+		return fmt.Errorf("flushing %d audit logs: %w", len(logs), err)
+	}
+	return nil
+}
+
+// requeue puts the logs of a failed write back in front of the buffer
+// and returns the number of the oldest logs dropped over the limit
+func (r *AsyncRepository) requeue(logs []*audit.Log) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buffer := make([]*audit.Log, 0, len(logs)+len(r.buffer))
+	buffer = append(buffer, logs...)
+	buffer = append(buffer, r.buffer...)
+
+	dropped := len(buffer) - r.bufferSize*asyncRetainFactor
+	if dropped < 0 {
+		dropped = 0
+	}
+	r.buffer = buffer[dropped:]
+	return dropped
+}
+
+func printFlushError(err error) {
+	fmt.Fprintf(os.Stderr, "Failed to flush audit logs: %v\n", err)
+}
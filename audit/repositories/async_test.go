@@ -0,0 +1,206 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/audit"
+	"github.com/odpf/salt/audit/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type batchRepositoryStub struct {
+	mu      sync.Mutex
+	batches [][]*audit.Log
+	err     error
+}
+
+func (r *batchRepositoryStub) Init(context.Context) error {
+	return nil
+}
+
+func (r *batchRepositoryStub) BatchInsert(_ context.Context, logs []*audit.Log) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.batches = append(r.batches, logs)
+	return nil
+}
+
+func (r *batchRepositoryStub) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+func (r *batchRepositoryStub) getBatches() [][]*audit.Log {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.batches
+}
+
+func TestAsyncRepository(t *testing.T) {
+	t.Run("should flush when buffer size is reached", func(t *testing.T) {
+		stub := &batchRepositoryStub{}
+		r := repositories.NewAsyncRepository(stub,
+			repositories.WithBufferSize(2),
+			repositories.WithFlushInterval(time.Hour),
+		)
+		defer r.Close(context.Background())
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "1", Actor: "user@example.com"}))
+		assert.Empty(t, stub.getBatches())
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "2", Actor: "user@example.com"}))
+		batches := stub.getBatches()
+		assert.Len(t, batches, 1)
+		assert.Len(t, batches[0], 2)
+	})
+
+	t.Run("should return error of invalid log without buffering it", func(t *testing.T) {
+		stub := &batchRepositoryStub{}
+		r := repositories.NewAsyncRepository(stub,
+			repositories.WithBufferSize(2),
+			repositories.WithFlushInterval(time.Hour),
+		)
+		defer r.Close(context.Background())
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "1", Actor: "user@example.com"}))
+		err := r.Insert(context.Background(), &audit.Log{Action: "2"})
+		assert.ErrorIs(t, err, audit.ErrMissingActor)
+		assert.Empty(t, stub.getBatches())
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "3", Actor: "user@example.com"}))
+		batches := stub.getBatches()
+		assert.Len(t, batches, 1)
+		assert.Equal(t, []string{"1", "3"}, actions(batches[0]))
+	})
+
+	t.Run("should flush periodically", func(t *testing.T) {
+		stub := &batchRepositoryStub{}
+		r := repositories.NewAsyncRepository(stub,
+			repositories.WithBufferSize(100),
+			repositories.WithFlushInterval(10*time.Millisecond),
+		)
+		defer r.Close(context.Background())
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "1", Actor: "user@example.com"}))
+		assert.Eventually(t, func() bool {
+			return len(stub.getBatches()) == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("should flush remaining logs on close", func(t *testing.T) {
+		stub := &batchRepositoryStub{}
+		r := repositories.NewAsyncRepository(stub,
+			repositories.WithBufferSize(100),
+			repositories.WithFlushInterval(time.Hour),
+		)
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "1", Actor: "user@example.com"}))
+		assert.NoError(t, r.Close(context.Background()))
+
+		batches := stub.getBatches()
+		assert.Len(t, batches, 1)
+		assert.Len(t, batches[0], 1)
+
+		err := r.Insert(context.Background(), &audit.Log{Action: "2", Actor: "user@example.com"})
+		assert.ErrorIs(t, err, repositories.ErrRepositoryClosed)
+	})
+
+	t.Run("should keep logs of failed flush for next flush", func(t *testing.T) {
+		expectedError := errors.New("test error")
+		stub := &batchRepositoryStub{err: expectedError}
+		r := repositories.NewAsyncRepository(stub,
+			repositories.WithBufferSize(2),
+			repositories.WithFlushInterval(time.Hour),
+		)
+		defer r.Close(context.Background())
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "1", Actor: "user@example.com"}))
+		err := r.Insert(context.Background(), &audit.Log{Action: "2", Actor: "user@example.com"})
+		assert.ErrorIs(t, err, expectedError)
+		assert.Empty(t, stub.getBatches())
+
+		stub.setErr(nil)
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "3", Actor: "user@example.com"}))
+		batches := stub.getBatches()
+		if assert.Len(t, batches, 1) {
+			assert.Equal(t, []string{"1", "2", "3"}, actions(batches[0]))
+		}
+	})
+
+	t.Run("should drop oldest logs over the limit while flushes fail", func(t *testing.T) {
+		expectedError := errors.New("test error")
+		stub := &batchRepositoryStub{err: expectedError}
+		r := repositories.NewAsyncRepository(stub,
+			repositories.WithBufferSize(1),
+			repositories.WithFlushInterval(time.Hour),
+		)
+		defer r.Close(context.Background())
+
+		var err error
+		for i := 0; i < 12; i++ {
+			err = r.Insert(context.Background(), &audit.Log{Action: strconv.Itoa(i), Actor: "user@example.com"})
+		}
+		assert.EqualError(t, err, "flushing 11 audit logs, dropped 1: test error")
+
+		stub.setErr(nil)
+		assert.NoError(t, r.Flush(context.Background()))
+		batches := stub.getBatches()
+		if assert.Len(t, batches, 1) {
+			assert.Equal(t, []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}, actions(batches[0]))
+		}
+	})
+
+	t.Run("should ignore non positive flush interval and buffer size", func(t *testing.T) {
+		stub := &batchRepositoryStub{}
+		r := repositories.NewAsyncRepository(stub,
+			repositories.WithBufferSize(-1),
+			repositories.WithFlushInterval(0),
+		)
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "1", Actor: "user@example.com"}))
+		assert.NoError(t, r.Close(context.Background()))
+		assert.Len(t, stub.getBatches(), 1)
+	})
+
+	t.Run("should report periodic flush errors to handler", func(t *testing.T) {
+		expectedError := errors.New("test error")
+		stub := &batchRepositoryStub{err: expectedError}
+		errCh := make(chan error, 1)
+		r := repositories.NewAsyncRepository(stub,
+			repositories.WithBufferSize(100),
+			repositories.WithFlushInterval(10*time.Millisecond),
+			repositories.WithFlushErrorHandler(func(err error) {
+				select {
+				case errCh <- err:
+				default:
+				}
+			}),
+		)
+		defer r.Close(context.Background())
+
+		assert.NoError(t, r.Insert(context.Background(), &audit.Log{Action: "1", Actor: "user@example.com"}))
+		select {
+		case err := <-errCh:
+			assert.ErrorIs(t, err, expectedError)
+		case <-time.After(time.Second):
+			t.Fatal("expected flush error")
+		}
+	})
+}
+
+func actions(logs []*audit.Log) []string {
+	actions := make([]string, 0, len(logs))
+	for _, l := range logs {
+		actions = append(actions, l.Action)
+	}
+	return actions
+}
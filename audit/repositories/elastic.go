@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/odpf/salt/audit"
+)
+
+const defaultElasticIndex = "audit_logs"
+
+var elasticMappings = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"timestamp": map[string]interface{}{"type": "date"},
+		"actor":     map[string]interface{}{"type": "keyword"},
+		"action":    map[string]interface{}{"type": "keyword"},
+		"severity":  map[string]interface{}{"type": "keyword"},
+	},
+}
+
+type auditElasticModel struct {
+	Timestamp   time.Time   `json:"timestamp"`
+	Action      string      `json:"action"`
+	Actor       string      `json:"actor"`
+	Severity    string      `json:"severity"`
+	Data        interface{} `json:"data"`
+	DataSchema  string      `json:"data_schema,omitempty"`
+	DataVersion int         `json:"data_version,omitempty"`
+	Metadata    interface{} `json:"metadata"`
+}
+
+type ElasticOption func(*ElasticRepository)
+
+// WithElasticIndex sets the index the logs are written to,
+// defaults to audit_logs
+func WithElasticIndex(name string) ElasticOption {
+	return func(r *ElasticRepository) {
+		r.index = name
+	}
+}
+
+// WithElasticDateRolledIndex writes each log to an index suffixed with
+// its timestamp in the layout, e.g. audit_logs-2021.10.01 with the
+// layout "2006.01.02", so old logs can be dropped by deleting indices
+func WithElasticDateRolledIndex(layout string) ElasticOption {
+	return func(r *ElasticRepository) {
+		r.dateLayout = layout
+	}
+}
+
+type ElasticRepository struct {
+	client     *elasticsearch.Client
+	index      string
+	dateLayout string
+}
+
+func NewElasticRepository(client *elasticsearch.Client, opts ...ElasticOption) *ElasticRepository {
+	r := &ElasticRepository{
+		client: client,
+		index:  defaultElasticIndex,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Init creates the index with the mappings of the indexed fields,
+// or an index template for date rolled indices
+func (r *ElasticRepository) Init(ctx context.Context) error {
+	if r.dateLayout != "" {
+		body, err := json.Marshal(map[string]interface{}{
+			"index_patterns": []string{r.index + "-*"},
+			"template":       map[string]interface{}{"mappings": elasticMappings},
+		})
+		if err != nil {
+			return err
+		}
+
+		res, err := r.client.Indices.PutIndexTemplate(r.index, bytes.NewReader(body),
+			r.client.Indices.PutIndexTemplate.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("creating audit index template in elasticsearch: %w", err)
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			return fmt.Errorf("creating audit index template in elasticsearch: %w", responseError(res))
+		}
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"mappings": elasticMappings})
+	if err != nil {
+		return err
+	}
+
+	res, err := r.client.Indices.Create(r.index,
+		r.client.Indices.Create.WithBody(bytes.NewReader(body)),
+		r.client.Indices.Create.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("creating audit index in elasticsearch: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		err := responseError(res)
+		if res.StatusCode == http.StatusBadRequest && strings.Contains(err.Error(), "resource_already_exists_exception") {
+			return nil
+		}
+		return fmt.Errorf("creating audit index in elasticsearch: %w", err)
+	}
+	return nil
+}
+
+func (r *ElasticRepository) Insert(ctx context.Context, l *audit.Log) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(&auditElasticModel{
+		Timestamp:   l.Timestamp,
+		Action:      l.Action,
+		Actor:       l.Actor,
+		Severity:    string(l.Severity),
+		Data:        l.Data,
+		DataSchema:  l.DataSchema,
+		DataVersion: l.DataVersion,
+		Metadata:    l.Metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling audit log: %w", err)
+	}
+
+	res, err := r.client.Index(r.indexFor(l), bytes.NewReader(body), r.client.Index.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("indexing to elasticsearch: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("indexing to elasticsearch: %w", responseError(res))
+	}
+	return nil
+}
+
+func (r *ElasticRepository) indexFor(l *audit.Log) string {
+	if r.dateLayout == "" {
+		return r.index
+	}
+	return r.index + "-" + l.Timestamp.UTC().Format(r.dateLayout)
+}
+
+func responseError(res *esapi.Response) error {
+	body, _ := ioutil.ReadAll(res.Body)
+	return fmt.Errorf("%s: %s", res.Status(), strings.TrimSpace(string(body)))
+}
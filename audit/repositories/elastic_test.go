@@ -0,0 +1,179 @@
+package repositories_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/odpf/salt/audit"
+	"github.com/odpf/salt/audit/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type elasticRequest struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+// elasticTransport records the requests and responds with the status and body
+type elasticTransport struct {
+	requests []elasticRequest
+	status   int
+	body     string
+}
+
+func (t *elasticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := elasticRequest{method: req.Method, path: req.URL.Path}
+	if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.body); err != nil {
+			return nil, err
+		}
+	}
+	t.requests = append(t.requests, r)
+
+	return &http.Response{
+		StatusCode: t.status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(t.body)),
+	}, nil
+}
+
+func newElasticClient(t *testing.T, transport *elasticTransport) *elasticsearch.Client {
+	t.Helper()
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Transport: transport})
+	assert.NoError(t, err)
+	return client
+}
+
+var expectedElasticMappings = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"timestamp": map[string]interface{}{"type": "date"},
+		"actor":     map[string]interface{}{"type": "keyword"},
+		"action":    map[string]interface{}{"type": "keyword"},
+		"severity":  map[string]interface{}{"type": "keyword"},
+	},
+}
+
+func TestElasticRepository(t *testing.T) {
+	t.Run("Init should create index with mappings", func(t *testing.T) {
+		transport := &elasticTransport{status: http.StatusOK, body: `{"acknowledged":true}`}
+		r := repositories.NewElasticRepository(newElasticClient(t, transport))
+
+		err := r.Init(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, []elasticRequest{{
+			method: http.MethodPut,
+			path:   "/audit_logs",
+			body:   map[string]interface{}{"mappings": expectedElasticMappings},
+		}}, transport.requests)
+	})
+
+	t.Run("Init should ignore existing index", func(t *testing.T) {
+		transport := &elasticTransport{
+			status: http.StatusBadRequest,
+			body:   `{"error":{"type":"resource_already_exists_exception"},"status":400}`,
+		}
+		r := repositories.NewElasticRepository(newElasticClient(t, transport))
+
+		err := r.Init(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Init should return error if index creation fails", func(t *testing.T) {
+		transport := &elasticTransport{
+			status: http.StatusForbidden,
+			body:   `{"error":{"type":"security_exception"},"status":403}`,
+		}
+		r := repositories.NewElasticRepository(newElasticClient(t, transport))
+
+		err := r.Init(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "security_exception")
+	})
+
+	t.Run("Init should create index template for date rolled indices", func(t *testing.T) {
+		transport := &elasticTransport{status: http.StatusOK, body: `{"acknowledged":true}`}
+		r := repositories.NewElasticRepository(newElasticClient(t, transport),
+			repositories.WithElasticIndex("guardian_audit"),
+			repositories.WithElasticDateRolledIndex("2006.01.02"),
+		)
+
+		err := r.Init(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, []elasticRequest{{
+			method: http.MethodPut,
+			path:   "/_index_template/guardian_audit",
+			body: map[string]interface{}{
+				"index_patterns": []interface{}{"guardian_audit-*"},
+				"template":       map[string]interface{}{"mappings": expectedElasticMappings},
+			},
+		}}, transport.requests)
+	})
+
+	log := &audit.Log{
+		Timestamp: time.Date(2021, 10, 1, 8, 30, 0, 0, time.UTC),
+		Action:    "action",
+		Actor:     "user@example.com",
+		Data:      map[string]interface{}{"foo": "bar"},
+		Metadata:  map[string]interface{}{"trace_id": "test-trace-id"},
+	}
+	expectedDocument := map[string]interface{}{
+		"timestamp": "2021-10-01T08:30:00Z",
+		"action":    "action",
+		"actor":     "user@example.com",
+		"severity":  "info",
+		"data":      map[string]interface{}{"foo": "bar"},
+		"metadata":  map[string]interface{}{"trace_id": "test-trace-id"},
+	}
+
+	t.Run("Insert should index log as a document", func(t *testing.T) {
+		transport := &elasticTransport{status: http.StatusCreated, body: `{"result":"created"}`}
+		r := repositories.NewElasticRepository(newElasticClient(t, transport))
+
+		err := r.Insert(context.Background(), log)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []elasticRequest{{
+			method: http.MethodPost,
+			path:   "/audit_logs/_doc",
+			body:   expectedDocument,
+		}}, transport.requests)
+	})
+
+	t.Run("Insert should index to date rolled index", func(t *testing.T) {
+		transport := &elasticTransport{status: http.StatusCreated, body: `{"result":"created"}`}
+		r := repositories.NewElasticRepository(newElasticClient(t, transport),
+			repositories.WithElasticDateRolledIndex("2006.01.02"),
+		)
+
+		err := r.Insert(context.Background(), log)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "/audit_logs-2021.10.01/_doc", transport.requests[0].path)
+	})
+
+	t.Run("Insert should return error if indexing fails", func(t *testing.T) {
+		transport := &elasticTransport{
+			status: http.StatusBadRequest,
+			body:   `{"error":{"type":"mapper_parsing_exception"},"status":400}`,
+		}
+		r := repositories.NewElasticRepository(newElasticClient(t, transport))
+
+		err := r.Insert(context.Background(), log)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "mapper_parsing_exception")
+	})
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/odpf/salt/audit"
+)
+
+type auditFileModel struct {
+	Timestamp   time.Time   `json:"timestamp"`
+	Action      string      `json:"action"`
+	Actor       string      `json:"actor"`
+	Severity    string      `json:"severity"`
+	Data        interface{} `json:"data"`
+	DataSchema  string      `json:"data_schema,omitempty"`
+	DataVersion int         `json:"data_version,omitempty"`
+	Metadata    interface{} `json:"metadata"`
+}
+
+// FileRepository appends the logs as JSON lines to a file, e.g. to
+// read the audit logs in local development without running a database.
+type FileRepository struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileRepository(path string) *FileRepository {
+	return &FileRepository{path: path}
+}
+
+// Init creates the file and its directory if they do not exist
+func (r *FileRepository) Init(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("creating audit log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("creating audit log file: %w", err)
+	}
+	return f.Close()
+}
+
+func (r *FileRepository) Insert(ctx context.Context, l *audit.Log) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(&auditFileModel{
+		Timestamp:   l.Timestamp,
+		Action:      l.Action,
+		Actor:       l.Actor,
+		Severity:    string(l.Severity),
+		Data:        l.Data,
+		DataSchema:  l.DataSchema,
+		DataVersion: l.DataVersion,
+		Metadata:    l.Metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling audit log: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening audit log file: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("writing to audit log file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing to audit log file: %w", err)
+	}
+	return nil
+}
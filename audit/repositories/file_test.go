@@ -0,0 +1,142 @@
+package repositories_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/audit"
+	"github.com/odpf/salt/audit/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	assert.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestFileRepository(t *testing.T) {
+	ctx := context.Background()
+
+	newRepository := func(t *testing.T) (*repositories.FileRepository, string) {
+		dir, err := ioutil.TempDir("", "audit")
+		assert.NoError(t, err)
+		path := filepath.Join(dir, "logs", "audit.jsonl")
+		return repositories.NewFileRepository(path), path
+	}
+
+	t.Run("Init should create the file and its directory", func(t *testing.T) {
+		r, path := newRepository(t)
+		defer os.RemoveAll(filepath.Dir(filepath.Dir(path)))
+
+		assert.NoError(t, r.Init(ctx))
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Zero(t, info.Size())
+	})
+
+	t.Run("Init should keep existing logs", func(t *testing.T) {
+		r, path := newRepository(t)
+		defer os.RemoveAll(filepath.Dir(filepath.Dir(path)))
+
+		assert.NoError(t, r.Init(ctx))
+		assert.NoError(t, r.Insert(ctx, &audit.Log{Action: "user.created", Actor: "user@example.com"}))
+		assert.NoError(t, r.Init(ctx))
+		assert.Len(t, readLines(t, path), 1)
+	})
+
+	t.Run("Insert should append a JSON line for each log", func(t *testing.T) {
+		r, path := newRepository(t)
+		defer os.RemoveAll(filepath.Dir(filepath.Dir(path)))
+		assert.NoError(t, r.Init(ctx))
+
+		timestamp := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+		logs := []*audit.Log{
+			{
+				Timestamp:   timestamp,
+				Action:      "user.created",
+				Actor:       "user@example.com",
+				Data:        map[string]interface{}{"name": "foo"},
+				DataSchema:  "user",
+				DataVersion: 2,
+				Metadata:    map[string]interface{}{"app_name": "guardian"},
+			},
+			{
+				Timestamp: timestamp,
+				Action:    "user.deleted",
+				Actor:     "admin@example.com",
+				Severity:  audit.SeverityCritical,
+			},
+		}
+		for _, l := range logs {
+			assert.NoError(t, r.Insert(ctx, l))
+		}
+
+		assert.Equal(t, []map[string]interface{}{
+			{
+				"timestamp":    "2021-10-01T12:00:00Z",
+				"action":       "user.created",
+				"actor":        "user@example.com",
+				"severity":     "info",
+				"data":         map[string]interface{}{"name": "foo"},
+				"data_schema":  "user",
+				"data_version": float64(2),
+				"metadata":     map[string]interface{}{"app_name": "guardian"},
+			},
+			{
+				"timestamp": "2021-10-01T12:00:00Z",
+				"action":    "user.deleted",
+				"actor":     "admin@example.com",
+				"severity":  "critical",
+				"data":      nil,
+				"metadata":  nil,
+			},
+		}, readLines(t, path))
+	})
+
+	t.Run("Insert should not interleave concurrent writes", func(t *testing.T) {
+		r, path := newRepository(t)
+		defer os.RemoveAll(filepath.Dir(filepath.Dir(path)))
+		assert.NoError(t, r.Init(ctx))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, r.Insert(ctx, &audit.Log{Action: "user.created", Actor: "user@example.com"}))
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, readLines(t, path), 50)
+	})
+
+	t.Run("Insert should return error for invalid log", func(t *testing.T) {
+		r, path := newRepository(t)
+		defer os.RemoveAll(filepath.Dir(filepath.Dir(path)))
+		assert.NoError(t, r.Init(ctx))
+
+		err := r.Insert(ctx, &audit.Log{Actor: "user@example.com"})
+		assert.ErrorIs(t, err, audit.ErrMissingAction)
+	})
+}
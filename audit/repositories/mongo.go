@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/odpf/salt/audit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type auditMongoModel struct {
+	Timestamp   time.Time   `bson:"timestamp"`
+	Action      string      `bson:"action"`
+	Actor       string      `bson:"actor"`
+	Severity    string      `bson:"severity"`
+	Data        interface{} `bson:"data"`
+	DataSchema  string      `bson:"data_schema,omitempty"`
+	DataVersion int         `bson:"data_version,omitempty"`
+	Metadata    interface{} `bson:"metadata"`
+}
+
+type MongoRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoRepository(collection *mongo.Collection) *MongoRepository {
+	return &MongoRepository{collection}
+}
+
+func (r *MongoRepository) Init(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "timestamp", Value: 1}}},
+		{Keys: bson.D{{Key: "actor", Value: 1}}},
+		{Keys: bson.D{{Key: "action", Value: 1}}},
+		{Keys: bson.D{{Key: "severity", Value: 1}}},
+	}
+	if _, err := r.collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("creating audit indexes in mongo collection: %w", err)
+	}
+	return nil
+}
+
+func (r *MongoRepository) Insert(ctx context.Context, l *audit.Log) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	m := &auditMongoModel{
+		Timestamp:   l.Timestamp,
+		Action:      l.Action,
+		Actor:       l.Actor,
+		Severity:    string(l.Severity),
+		Data:        l.Data,
+		DataSchema:  l.DataSchema,
+		DataVersion: l.DataVersion,
+		Metadata:    l.Metadata,
+	}
+
+	if _, err := r.collection.InsertOne(ctx, m); err != nil {
+		return fmt.Errorf("inserting to mongo collection: %w", err)
+	}
+
+	return nil
+}
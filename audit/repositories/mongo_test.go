@@ -0,0 +1,83 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/audit"
+	"github.com/odpf/salt/audit/repositories"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("Init should create indexes", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repositories.NewMongoRepository(mt.Coll)
+
+		err := r.Init(context.Background())
+		assert.NoError(mt, err)
+
+		cmd := mt.GetStartedEvent().Command
+		indexes := cmd.Lookup("indexes").Array()
+		values, err := indexes.Values()
+		assert.NoError(mt, err)
+
+		var keys []string
+		for _, v := range values {
+			key := v.Document().Lookup("key").Document()
+			elems, err := key.Elements()
+			assert.NoError(mt, err)
+			keys = append(keys, elems[0].Key())
+		}
+		assert.Equal(mt, []string{"timestamp", "actor", "action", "severity"}, keys)
+	})
+
+	mt.Run("Insert should write log as a document", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repositories.NewMongoRepository(mt.Coll)
+
+		timestamp := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+		err := r.Insert(context.Background(), &audit.Log{
+			Timestamp: timestamp,
+			Action:    "action",
+			Actor:     "user@example.com",
+			Severity:  audit.SeverityCritical,
+			Data:      map[string]interface{}{"foo": "bar"},
+			Metadata:  map[string]interface{}{"trace_id": "test-trace-id"},
+		})
+		assert.NoError(mt, err)
+
+		doc := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(mt, timestamp, doc.Lookup("timestamp").Time().UTC())
+		assert.Equal(mt, "action", doc.Lookup("action").StringValue())
+		assert.Equal(mt, "user@example.com", doc.Lookup("actor").StringValue())
+		assert.Equal(mt, "critical", doc.Lookup("severity").StringValue())
+		assert.Equal(mt, "bar", doc.Lookup("data", "foo").StringValue())
+		assert.Equal(mt, "test-trace-id", doc.Lookup("metadata", "trace_id").StringValue())
+	})
+
+	mt.Run("Insert should return error if log is invalid", func(mt *mtest.T) {
+		r := repositories.NewMongoRepository(mt.Coll)
+
+		err := r.Insert(context.Background(), &audit.Log{Action: "action"})
+		assert.ErrorIs(mt, err, audit.ErrMissingActor)
+	})
+
+	mt.Run("Insert should return error if write fails", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		r := repositories.NewMongoRepository(mt.Coll)
+
+		err := r.Insert(context.Background(), &audit.Log{Action: "action", Actor: "user@example.com"})
+		assert.True(mt, mongo.IsDuplicateKeyError(err))
+	})
+}
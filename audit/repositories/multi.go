@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/odpf/salt/audit"
+)
+
+// Repository stores audit logs, it is implemented by
+// all the repositories in this package
+type Repository interface {
+	Init(context.Context) error
+	Insert(context.Context, *audit.Log) error
+}
+
+// MultiError holds the errors of the repositories that failed
+type MultiError []error
+
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches the target
+func (e MultiError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type MultiOption func(*MultiRepository)
+
+// WithBestEffort makes Insert succeed if the log is written to at
+// least one repository, the errors of the failed repositories are
+// passed to the handler instead
+func WithBestEffort(errorHandler func(error)) MultiOption {
+	return func(r *MultiRepository) {
+		r.bestEffort = true
+		if errorHandler != nil {
+			r.errorHandler = errorHandler
+		}
+	}
+}
+
+// MultiRepository writes the logs to all the repositories, e.g. to
+// postgres for compliance and elasticsearch for search. By default
+// Insert fails if writing to any of the repositories fails.
+type MultiRepository struct {
+	repositories []Repository
+	bestEffort   bool
+	errorHandler func(error)
+}
+
+func NewMultiRepository(repositories []Repository, opts ...MultiOption) *MultiRepository {
+	r := &MultiRepository{
+		repositories: repositories,
+		errorHandler: func(error) {},
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Init initializes all the repositories and returns
+// the errors of the ones that failed
+func (r *MultiRepository) Init(ctx context.Context) error {
+	var errs MultiError
+	for _, repo := range r.repositories {
+		if err := repo.Init(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Insert writes the log to all the repositories, continuing
+// if writing to one of them fails
+func (r *MultiRepository) Insert(ctx context.Context, l *audit.Log) error {
+	var errs MultiError
+	for _, repo := range r.repositories {
+		if err := repo.Insert(ctx, l); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if r.bestEffort && len(errs) < len(r.repositories) {
+		r.errorHandler(errs)
+		return nil
+	}
+	return errs
+}
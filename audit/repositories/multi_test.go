@@ -0,0 +1,91 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/odpf/salt/audit"
+	"github.com/odpf/salt/audit/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepository struct {
+	logs    []*audit.Log
+	initErr error
+	err     error
+}
+
+func (r *memoryRepository) Init(context.Context) error {
+	return r.initErr
+}
+
+func (r *memoryRepository) Insert(_ context.Context, l *audit.Log) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.logs = append(r.logs, l)
+	return nil
+}
+
+func TestMultiRepository(t *testing.T) {
+	log := &audit.Log{Action: "action", Actor: "user@example.com"}
+
+	t.Run("Init should initialize all repositories", func(t *testing.T) {
+		initErr := errors.New("connection refused")
+		first, second := &memoryRepository{initErr: initErr}, &memoryRepository{}
+		r := repositories.NewMultiRepository([]repositories.Repository{first, second})
+
+		err := r.Init(context.Background())
+		assert.ErrorIs(t, err, initErr)
+	})
+
+	t.Run("Insert should write to all repositories", func(t *testing.T) {
+		first, second := &memoryRepository{}, &memoryRepository{}
+		r := repositories.NewMultiRepository([]repositories.Repository{first, second})
+
+		err := r.Insert(context.Background(), log)
+		assert.NoError(t, err)
+		assert.Equal(t, []*audit.Log{log}, first.logs)
+		assert.Equal(t, []*audit.Log{log}, second.logs)
+	})
+
+	t.Run("Insert should continue and combine errors on failure", func(t *testing.T) {
+		firstErr, thirdErr := errors.New("first failed"), errors.New("third failed")
+		first, second, third := &memoryRepository{err: firstErr}, &memoryRepository{}, &memoryRepository{err: thirdErr}
+		r := repositories.NewMultiRepository([]repositories.Repository{first, second, third})
+
+		err := r.Insert(context.Background(), log)
+		assert.EqualError(t, err, "first failed; third failed")
+		assert.ErrorIs(t, err, firstErr)
+		assert.ErrorIs(t, err, thirdErr)
+		assert.Equal(t, []*audit.Log{log}, second.logs)
+	})
+
+	t.Run("Insert should succeed in best effort mode if any repository succeeds", func(t *testing.T) {
+		insertErr := errors.New("connection refused")
+		first, second := &memoryRepository{err: insertErr}, &memoryRepository{}
+		var handledErr error
+		r := repositories.NewMultiRepository([]repositories.Repository{first, second},
+			repositories.WithBestEffort(func(err error) {
+				handledErr = err
+			}),
+		)
+
+		err := r.Insert(context.Background(), log)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, handledErr, insertErr)
+		assert.Equal(t, []*audit.Log{log}, second.logs)
+	})
+
+	t.Run("Insert should fail in best effort mode if all repositories fail", func(t *testing.T) {
+		insertErr := errors.New("connection refused")
+		first, second := &memoryRepository{err: insertErr}, &memoryRepository{err: insertErr}
+		r := repositories.NewMultiRepository([]repositories.Repository{first, second},
+			repositories.WithBestEffort(nil),
+		)
+
+		err := r.Insert(context.Background(), log)
+		assert.ErrorIs(t, err, insertErr)
+	})
+}
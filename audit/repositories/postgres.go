@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,53 +12,334 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	defaultBatchSize      = 100
+	defaultPurgeChunkSize = 1000
+	defaultTableName      = "audit_logs"
+)
+
 type auditPostgresModel struct {
-	Timestamp time.Time
-	Action    string
-	Actor     string
-	Data      datatypes.JSON
-	Metadata  datatypes.JSON
+	Timestamp   time.Time
+	Action      string
+	Actor       string
+	Severity    string `gorm:"default:'info';not null"`
+	Data        datatypes.JSON
+	DataSchema  string
+	DataVersion int
+	Metadata    datatypes.JSON
 }
 
 func (a auditPostgresModel) TableName() string {
-	return "audit_logs"
+	return defaultTableName
+}
+
+// Marshaler encodes Log.Data and Log.Metadata into the JSON stored in postgres
+type Marshaler func(interface{}) ([]byte, error)
+
+type PostgresOption func(*PostgresRepository)
+
+// WithMarshaler overrides the default json.Marshal used to encode
+// Log.Data and Log.Metadata, e.g. to use protojson or redact fields
+func WithMarshaler(fn Marshaler) PostgresOption {
+	return func(r *PostgresRepository) {
+		r.marshal = fn
+	}
+}
+
+// WithInsertTimeout bounds the time each insert may take,
+// unless the context of the insert has an earlier deadline
+func WithInsertTimeout(d time.Duration) PostgresOption {
+	return func(r *PostgresRepository) {
+		r.insertTimeout = d
+	}
+}
+
+// WithTableName stores the logs in the table instead of `audit_logs`,
+// e.g. to use a table per service in a shared database
+func WithTableName(name string) PostgresOption {
+	return func(r *PostgresRepository) {
+		r.tableName = name
+	}
+}
+
+// WithDataRegistry decodes the data of listed logs into the type
+// registered for their schema, data of unregistered schemas is
+// decoded as if no registry was set
+func WithDataRegistry(registry *audit.DataRegistry) PostgresOption {
+	return func(r *PostgresRepository) {
+		r.dataRegistry = registry
+	}
 }
 
 type PostgresRepository struct {
-	db *gorm.DB
+	db            *gorm.DB
+	marshal       Marshaler
+	insertTimeout time.Duration
+	tableName     string
+	dataRegistry  *audit.DataRegistry
 }
 
-func NewPostgresRepository(db *gorm.DB) *PostgresRepository {
-	return &PostgresRepository{db}
+func NewPostgresRepository(db *gorm.DB, opts ...PostgresOption) *PostgresRepository {
+	r := &PostgresRepository{
+		db:        db,
+		marshal:   json.Marshal,
+		tableName: defaultTableName,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
 }
 
 func (r *PostgresRepository) Init(ctx context.Context) error {
-	if err := r.db.WithContext(ctx).AutoMigrate(&auditPostgresModel{}); err != nil {
+	db := r.table(ctx)
+	if err := db.AutoMigrate(&auditPostgresModel{}); err != nil {
 		return fmt.Errorf("migrating audit model to postgres db: %w", err)
 	}
+
+	// gorm names indexes after the default table name, index
+	// names must be unique in the schema so name it after the table
+	index := fmt.Sprintf("idx_%s_severity", r.tableName)
+	if !db.Migrator().HasIndex(&auditPostgresModel{}, index) {
+		query := fmt.Sprintf(`CREATE INDEX "%s" ON "%s" ("severity")`, index, r.tableName)
+		if err := r.db.WithContext(ctx).Exec(query).Error; err != nil {
+			return fmt.Errorf("creating audit index in postgres db: %w", err)
+		}
+	}
 	return nil
 }
 
 func (r *PostgresRepository) Insert(ctx context.Context, l *audit.Log) error {
-	data, err := json.Marshal(l.Data)
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	m, err := r.toPostgresModel(l)
 	if err != nil {
-		return fmt.Errorf("marshaling data: %w", err)
+		return err
 	}
-	metadata, err := json.Marshal(l.Metadata)
+
+	ctx, cancel, err := r.insertContext(ctx)
 	if err != nil {
-		return fmt.Errorf("marshaling metadata: %w", err)
+		return err
+	}
+	defer cancel()
+
+	if err := r.table(ctx).Create(m).Error; err != nil {
+		return fmt.Errorf("inserting to db: %w", contextErr(ctx, err))
 	}
-	m := &auditPostgresModel{
-		Timestamp: l.Timestamp,
-		Action:    l.Action,
-		Actor:     l.Actor,
-		Data:      datatypes.JSON(data),
-		Metadata:  datatypes.JSON(metadata),
+
+	return nil
+}
+
+// BatchInsert inserts multiple logs using multi-row inserts
+// of at most defaultBatchSize rows each. No log is inserted
+// if any of them is invalid.
+func (r *PostgresRepository) BatchInsert(ctx context.Context, logs []*audit.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	models := make([]*auditPostgresModel, 0, len(logs))
+	for i, l := range logs {
+		if err := l.Validate(); err != nil {
+			return fmt.Errorf("log %d: %w", i, err)
+		}
+		m, err := r.toPostgresModel(l)
+		if err != nil {
+			return err
+		}
+		models = append(models, m)
+	}
+
+	ctx, cancel, err := r.insertContext(ctx)
+	if err != nil {
+		return err
 	}
+	defer cancel()
 
-	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
-		return fmt.Errorf("inserting to db: %w", err)
+	if err := r.table(ctx).CreateInBatches(models, defaultBatchSize).Error; err != nil {
+		return fmt.Errorf("batch inserting to db: %w", contextErr(ctx, err))
 	}
 
 	return nil
 }
+
+// List returns logs matching the filter ordered by the most recent first
+func (r *PostgresRepository) List(ctx context.Context, filter audit.Filter) ([]audit.Log, error) {
+	db := r.table(ctx)
+	if filter.Actor != "" {
+		db = db.Where(`"actor" = ?`, filter.Actor)
+	}
+	if filter.Action != "" {
+		db = db.Where(`"action" = ?`, filter.Action)
+	}
+	if filter.Severity != "" {
+		db = db.Where(`"severity" = ?`, filter.Severity)
+	}
+	if !filter.StartTime.IsZero() {
+		db = db.Where(`"timestamp" >= ?`, filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		db = db.Where(`"timestamp" < ?`, filter.EndTime)
+	}
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+
+	var models []*auditPostgresModel
+	if err := db.Order(`"timestamp" DESC`).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("listing audit logs from db: %w", err)
+	}
+
+	logs := make([]audit.Log, 0, len(models))
+	for _, m := range models {
+		l, err := r.toLog(m)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *l)
+	}
+
+	return logs, nil
+}
+
+// Purge deletes logs with timestamp older than the given time in chunks of
+// defaultPurgeChunkSize rows, to avoid long running transactions on big tables,
+// and returns the number of deleted logs
+func (r *PostgresRepository) Purge(ctx context.Context, olderThan time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM "%[1]s" WHERE ctid IN (SELECT ctid FROM "%[1]s" WHERE "timestamp" < ? LIMIT ?)`, r.tableName)
+
+	var deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		res := r.db.WithContext(ctx).Exec(query, olderThan, defaultPurgeChunkSize)
+		if res.Error != nil {
+			return deleted, fmt.Errorf("purging audit logs from db: %w", res.Error)
+		}
+		deleted += res.RowsAffected
+
+		if res.RowsAffected < defaultPurgeChunkSize {
+			return deleted, nil
+		}
+	}
+}
+
+// PurgePeriodically purges logs older than the retention duration on every
+// interval until the context is done, errors are passed to errorHandler if set.
+// It blocks and should be run in its own goroutine.
+func (r *PostgresRepository) PurgePeriodically(ctx context.Context, retention, interval time.Duration, errorHandler func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Purge(ctx, audit.TimeNow().Add(-retention)); err != nil && errorHandler != nil {
+				errorHandler(err)
+			}
+		}
+	}
+}
+
+// table returns the db scoped to the table of the logs
+func (r *PostgresRepository) table(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table(r.tableName)
+}
+
+// insertContext returns the context error without querying the db
+// if it is already done, and applies the insert timeout if set
+func (r *PostgresRepository) insertContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if r.insertTimeout <= 0 {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.insertTimeout)
+	return ctx, cancel, nil
+}
+
+// contextErr returns the context error if the context is done, as the
+// driver errors for canceled queries do not wrap the context error
+func contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+func (r *PostgresRepository) toPostgresModel(l *audit.Log) (*auditPostgresModel, error) {
+	data, err := r.marshal(l.Data)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling data: %w", err)
+	}
+	metadata, err := r.marshal(l.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling metadata: %w", err)
+	}
+
+	return &auditPostgresModel{
+		Timestamp:   l.Timestamp,
+		Action:      l.Action,
+		Actor:       l.Actor,
+		Severity:    string(l.Severity),
+		Data:        datatypes.JSON(data),
+		DataSchema:  l.DataSchema,
+		DataVersion: l.DataVersion,
+		Metadata:    datatypes.JSON(metadata),
+	}, nil
+}
+
+func (r *PostgresRepository) toLog(a *auditPostgresModel) (*audit.Log, error) {
+	data, err := r.decodeData(a)
+	if err != nil {
+		return nil, err
+	}
+	var metadata interface{}
+	if len(a.Metadata) > 0 {
+		if err := json.Unmarshal(a.Metadata, &metadata); err != nil {
+			return nil, fmt.Errorf("unmarshaling metadata: %w", err)
+		}
+	}
+
+	return &audit.Log{
+		Timestamp:   a.Timestamp,
+		Action:      a.Action,
+		Actor:       a.Actor,
+		Severity:    audit.Severity(a.Severity),
+		Data:        data,
+		DataSchema:  a.DataSchema,
+		DataVersion: a.DataVersion,
+		Metadata:    metadata,
+	}, nil
+}
+
+// decodeData decodes the data into the type registered for its
+// schema if any, or into the generic JSON types otherwise
+func (r *PostgresRepository) decodeData(a *auditPostgresModel) (interface{}, error) {
+	if len(a.Data) == 0 {
+		return nil, nil
+	}
+
+	if r.dataRegistry != nil && a.DataSchema != "" {
+		data, err := r.dataRegistry.Decode(a.DataSchema, a.DataVersion, a.Data)
+		if !errors.Is(err, audit.ErrUnknownDataSchema) {
+			return data, err
+		}
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(a.Data, &data); err != nil {
+		return nil, fmt.Errorf("unmarshaling data: %w", err)
+	}
+	return data, nil
+}
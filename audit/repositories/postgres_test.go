@@ -6,6 +6,7 @@ import (
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/odpf/salt/audit"
@@ -20,6 +21,7 @@ type PostgresRepositoryTestSuite struct {
 
 	dbMock     sqlmock.Sqlmock
 	dbConn     *sql.DB
+	gormDB     *gorm.DB
 	repository *repositories.PostgresRepository
 }
 
@@ -27,6 +29,14 @@ func TestPostgresRepository(t *testing.T) {
 	suite.Run(t, new(PostgresRepositoryTestSuite))
 }
 
+func newLog() *audit.Log {
+	return &audit.Log{
+		Timestamp: time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC),
+		Action:    "action",
+		Actor:     "user@example.com",
+	}
+}
+
 func (s *PostgresRepositoryTestSuite) setupTest() {
 	db, dbMock, err := sqlmock.New()
 	s.Require().NoError(err)
@@ -36,6 +46,7 @@ func (s *PostgresRepositoryTestSuite) setupTest() {
 		Conn: db,
 	}), &gorm.Config{})
 	s.Require().NoError(err)
+	s.gormDB = gormDB
 
 	s.repository = repositories.NewPostgresRepository(gormDB)
 }
@@ -49,7 +60,9 @@ func (s *PostgresRepositoryTestSuite) TestInit() {
 		s.setupTest()
 		defer s.cleanupTest()
 
-		s.dbMock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE "audit_logs" ("timestamp" timestamptz,"action" text,"actor" text,"data" JSONB,"metadata" JSONB)`)).
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE "audit_logs" ("timestamp" timestamptz,"action" text,"actor" text,"severity" text NOT NULL DEFAULT 'info',"data" JSONB,"data_schema" text,"data_version" bigint,"metadata" JSONB)`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX "idx_audit_logs_severity" ON "audit_logs" ("severity")`)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		err := s.repository.Init(context.Background())
@@ -57,6 +70,32 @@ func (s *PostgresRepositoryTestSuite) TestInit() {
 		s.dbMock.ExpectationsWereMet()
 	})
 
+	s.Run("should add severity with default to existing table", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+		s.dbMock.MatchExpectationsInOrder(false)
+
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM information_schema.tables`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT CURRENT_DATABASE()`)).
+			WillReturnRows(sqlmock.NewRows([]string{"current_database"}).AddRow("guardian"))
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`FROM information_schema.columns`)).
+			WillReturnRows(sqlmock.NewRows([]string{"column_name", "is_nullable", "udt_name", "character_maximum_length",
+				"numeric_precision", "numeric_precision_radix", "numeric_scale", "datetime_precision"}))
+		// existing rows get the default severity
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`ALTER TABLE "audit_logs" ADD "severity" text NOT NULL DEFAULT 'info'`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		for i := 0; i < 7; i++ {
+			s.dbMock.ExpectExec(`ALTER TABLE "audit_logs" ADD`).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM pg_indexes`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		err := s.repository.Init(context.Background())
+		s.NoError(err)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
 	s.Run("should return error if migrate returns error", func() {
 		s.setupTest()
 		defer s.cleanupTest()
@@ -75,11 +114,11 @@ func (s *PostgresRepositoryTestSuite) TestInsert() {
 		s.setupTest()
 		defer s.cleanupTest()
 
-		l := &audit.Log{}
+		l := newLog()
 
 		s.dbMock.ExpectBegin()
-		s.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "audit_logs" ("timestamp","action","actor","data","metadata") VALUES ($1,$2,$3,$4,$5)`)).
-			WithArgs(l.Timestamp, l.Action, l.Actor, `null`, `null`).
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "audit_logs" ("timestamp","action","actor","severity","data","data_schema","data_version","metadata") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+			WithArgs(l.Timestamp, l.Action, l.Actor, "info", `null`, "", 0, `null`).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		s.dbMock.ExpectCommit()
 
@@ -88,13 +127,55 @@ func (s *PostgresRepositoryTestSuite) TestInsert() {
 		s.dbMock.ExpectationsWereMet()
 	})
 
+	s.Run("should insert severity of the log", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		l := newLog()
+		l.Severity = audit.SeverityWarning
+
+		s.dbMock.ExpectBegin()
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "audit_logs" ("timestamp","action","actor","severity","data","data_schema","data_version","metadata") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+			WithArgs(l.Timestamp, l.Action, l.Actor, "warning", `null`, "", 0, `null`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		s.dbMock.ExpectCommit()
+
+		err := s.repository.Insert(context.Background(), l)
+		s.NoError(err)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should store output of custom marshaler", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		var marshaled []interface{}
+		s.repository = repositories.NewPostgresRepository(s.gormDB, repositories.WithMarshaler(func(v interface{}) ([]byte, error) {
+			marshaled = append(marshaled, v)
+			return []byte(`{"redacted":true}`), nil
+		}))
+		l := newLog()
+		l.Data = map[string]interface{}{"password": "secret"}
+		l.Metadata = map[string]interface{}{"trace_id": "test-trace-id"}
+
+		s.dbMock.ExpectBegin()
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "audit_logs" ("timestamp","action","actor","severity","data","data_schema","data_version","metadata") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+			WithArgs(l.Timestamp, l.Action, l.Actor, "info", `{"redacted":true}`, "", 0, `{"redacted":true}`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		s.dbMock.ExpectCommit()
+
+		err := s.repository.Insert(context.Background(), l)
+		s.NoError(err)
+		s.Equal([]interface{}{l.Data, l.Metadata}, marshaled)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
 	s.Run("should return error if data marshaling returns error", func() {
 		s.setupTest()
 		defer s.cleanupTest()
 
-		l := &audit.Log{
-			Data: make(chan int),
-		}
+		l := newLog()
+		l.Data = make(chan int)
 
 		err := s.repository.Insert(context.Background(), l)
 		s.EqualError(err, "marshaling data: json: unsupported type: chan int")
@@ -104,21 +185,32 @@ func (s *PostgresRepositoryTestSuite) TestInsert() {
 		s.setupTest()
 		defer s.cleanupTest()
 
-		l := &audit.Log{
-			Metadata: map[string]interface{}{
-				"foo": make(chan int),
-			},
+		l := newLog()
+		l.Metadata = map[string]interface{}{
+			"foo": make(chan int),
 		}
 
 		err := s.repository.Insert(context.Background(), l)
 		s.EqualError(err, "marshaling metadata: json: unsupported type: chan int")
 	})
 
+	s.Run("should return error if log is invalid", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		l := newLog()
+		l.Action = ""
+
+		err := s.repository.Insert(context.Background(), l)
+		s.ErrorIs(err, audit.ErrMissingAction)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
 	s.Run("should return error if db insert returns error", func() {
 		s.setupTest()
 		defer s.cleanupTest()
 
-		l := &audit.Log{}
+		l := newLog()
 
 		expectedError := errors.New("test error")
 		s.dbMock.ExpectBegin()
@@ -130,3 +222,387 @@ func (s *PostgresRepositoryTestSuite) TestInsert() {
 		s.dbMock.ExpectationsWereMet()
 	})
 }
+
+func (s *PostgresRepositoryTestSuite) TestInsertContext() {
+	s.Run("should return context error without querying db if context is canceled", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := s.repository.Insert(ctx, newLog())
+		s.ErrorIs(err, context.Canceled)
+
+		err = s.repository.BatchInsert(ctx, []*audit.Log{newLog()})
+		s.ErrorIs(err, context.Canceled)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should return deadline exceeded if insert takes longer than timeout", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		s.repository = repositories.NewPostgresRepository(s.gormDB, repositories.WithInsertTimeout(10*time.Millisecond))
+
+		s.dbMock.ExpectBegin()
+		s.dbMock.ExpectExec(".*").WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(1, 1))
+		s.dbMock.ExpectRollback()
+
+		err := s.repository.Insert(context.Background(), newLog())
+		s.ErrorIs(err, context.DeadlineExceeded)
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestBatchInsert() {
+	s.Run("should insert records in a single statement", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		logs := []*audit.Log{
+			{Timestamp: time.Now(), Action: "action-1", Actor: "user-1", Severity: audit.SeverityCritical},
+			{Timestamp: time.Now(), Action: "action-2", Actor: "user-2"},
+		}
+
+		s.dbMock.ExpectBegin()
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "audit_logs" ("timestamp","action","actor","severity","data","data_schema","data_version","metadata") VALUES ($1,$2,$3,$4,$5,$6,$7,$8),($9,$10,$11,$12,$13,$14,$15,$16)`)).
+			WithArgs(
+				logs[0].Timestamp, logs[0].Action, logs[0].Actor, "critical", `null`, "", 0, `null`,
+				logs[1].Timestamp, logs[1].Action, logs[1].Actor, "info", `null`, "", 0, `null`,
+			).
+			WillReturnResult(sqlmock.NewResult(2, 2))
+		s.dbMock.ExpectCommit()
+
+		err := s.repository.BatchInsert(context.Background(), logs)
+		s.NoError(err)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should do nothing if logs are empty", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		err := s.repository.BatchInsert(context.Background(), nil)
+		s.NoError(err)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should return error if data marshaling returns error", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		l := newLog()
+		l.Data = make(chan int)
+		logs := []*audit.Log{l}
+
+		err := s.repository.BatchInsert(context.Background(), logs)
+		s.EqualError(err, "marshaling data: json: unsupported type: chan int")
+	})
+
+	s.Run("should return error if a log is invalid", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		l := newLog()
+		l.Actor = ""
+		logs := []*audit.Log{newLog(), l}
+
+		err := s.repository.BatchInsert(context.Background(), logs)
+		s.ErrorIs(err, audit.ErrMissingActor)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should return error if db insert returns error", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		expectedError := errors.New("test error")
+		s.dbMock.ExpectBegin()
+		s.dbMock.ExpectExec(".*").WillReturnError(expectedError)
+		s.dbMock.ExpectRollback()
+
+		err := s.repository.BatchInsert(context.Background(), []*audit.Log{newLog()})
+		s.ErrorIs(err, expectedError)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestList() {
+	s.Run("should filter by actor", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		timestamp := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"timestamp", "action", "actor", "severity", "data", "metadata"}).
+			AddRow(timestamp, "action", "user@example.com", "info", `{"foo":"bar"}`, `{"trace_id":"test-trace-id"}`)
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_logs" WHERE "actor" = $1 ORDER BY "timestamp" DESC LIMIT 10 OFFSET 20`)).
+			WithArgs("user@example.com").
+			WillReturnRows(rows)
+
+		logs, err := s.repository.List(context.Background(), audit.Filter{
+			Actor:  "user@example.com",
+			Limit:  10,
+			Offset: 20,
+		})
+		s.NoError(err)
+		s.Equal([]audit.Log{
+			{
+				Timestamp: timestamp,
+				Action:    "action",
+				Actor:     "user@example.com",
+				Severity:  audit.SeverityInfo,
+				Data:      map[string]interface{}{"foo": "bar"},
+				Metadata:  map[string]interface{}{"trace_id": "test-trace-id"},
+			},
+		}, logs)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should filter by action and time range", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+		end := start.Add(24 * time.Hour)
+		rows := sqlmock.NewRows([]string{"timestamp", "action", "actor", "severity", "data", "metadata"}).
+			AddRow(start.Add(time.Hour), "action", "user@example.com", "info", `null`, `null`)
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_logs" WHERE "action" = $1 AND "timestamp" >= $2 AND "timestamp" < $3 ORDER BY "timestamp" DESC`)).
+			WithArgs("action", start, end).
+			WillReturnRows(rows)
+
+		logs, err := s.repository.List(context.Background(), audit.Filter{
+			Action:    "action",
+			StartTime: start,
+			EndTime:   end,
+		})
+		s.NoError(err)
+		s.Len(logs, 1)
+		s.Equal(start.Add(time.Hour), logs[0].Timestamp)
+		s.Nil(logs[0].Data)
+		s.Nil(logs[0].Metadata)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should filter by severity", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		timestamp := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"timestamp", "action", "actor", "severity", "data", "metadata"}).
+			AddRow(timestamp, "role.grant", "user@example.com", "critical", `null`, `null`)
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_logs" WHERE "severity" = $1 ORDER BY "timestamp" DESC`)).
+			WithArgs(audit.SeverityCritical).
+			WillReturnRows(rows)
+
+		logs, err := s.repository.List(context.Background(), audit.Filter{
+			Severity: audit.SeverityCritical,
+		})
+		s.NoError(err)
+		s.Len(logs, 1)
+		s.Equal(audit.SeverityCritical, logs[0].Severity)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should return error if db query returns error", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		expectedError := errors.New("test error")
+		s.dbMock.ExpectQuery(".*").WillReturnError(expectedError)
+
+		_, err := s.repository.List(context.Background(), audit.Filter{})
+		s.ErrorIs(err, expectedError)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestDataRegistry() {
+	type appeal struct {
+		ID     string `json:"id"`
+		Reason string `json:"reason"`
+	}
+	registry := audit.NewDataRegistry()
+	registry.Register("appeal", 2, appeal{})
+
+	s.Run("should round trip typed data through insert and list", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		s.repository = repositories.NewPostgresRepository(s.gormDB, repositories.WithDataRegistry(registry))
+		l := newLog()
+		l.Data = appeal{ID: "1", Reason: "access"}
+		l.DataSchema, l.DataVersion, _ = registry.SchemaOf(l.Data)
+
+		s.dbMock.ExpectBegin()
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "audit_logs" ("timestamp","action","actor","severity","data","data_schema","data_version","metadata") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+			WithArgs(l.Timestamp, l.Action, l.Actor, "info", `{"id":"1","reason":"access"}`, "appeal", 2, `null`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		s.dbMock.ExpectCommit()
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "audit_logs" ORDER BY "timestamp" DESC`)).
+			WillReturnRows(sqlmock.NewRows([]string{"timestamp", "action", "actor", "severity", "data", "data_schema", "data_version", "metadata"}).
+				AddRow(l.Timestamp, l.Action, l.Actor, "info", `{"id":"1","reason":"access"}`, "appeal", 2, `null`))
+
+		err := s.repository.Insert(context.Background(), l)
+		s.NoError(err)
+
+		logs, err := s.repository.List(context.Background(), audit.Filter{})
+		s.NoError(err)
+		s.Equal([]audit.Log{*l}, logs)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should decode data of unknown schema as json", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		s.repository = repositories.NewPostgresRepository(s.gormDB, repositories.WithDataRegistry(registry))
+		s.dbMock.ExpectQuery(".*").
+			WillReturnRows(sqlmock.NewRows([]string{"timestamp", "action", "actor", "severity", "data", "data_schema", "data_version", "metadata"}).
+				AddRow(time.Now(), "action", "user@example.com", "info", `{"id":"1"}`, "appeal", 1, `null`))
+
+		logs, err := s.repository.List(context.Background(), audit.Filter{})
+		s.NoError(err)
+		s.Len(logs, 1)
+		s.Equal(map[string]interface{}{"id": "1"}, logs[0].Data)
+		s.Equal("appeal", logs[0].DataSchema)
+		s.Equal(1, logs[0].DataVersion)
+	})
+
+	s.Run("should return error if data does not match registered type", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		s.repository = repositories.NewPostgresRepository(s.gormDB, repositories.WithDataRegistry(registry))
+		s.dbMock.ExpectQuery(".*").
+			WillReturnRows(sqlmock.NewRows([]string{"timestamp", "action", "actor", "severity", "data", "data_schema", "data_version", "metadata"}).
+				AddRow(time.Now(), "action", "user@example.com", "info", `{"id":1}`, "appeal", 2, `null`))
+
+		_, err := s.repository.List(context.Background(), audit.Filter{})
+		s.Error(err)
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestPurge() {
+	purgeQuery := regexp.QuoteMeta(`DELETE FROM "audit_logs" WHERE ctid IN (SELECT ctid FROM "audit_logs" WHERE "timestamp" < $1 LIMIT $2)`)
+
+	s.Run("should delete logs older than cutoff in chunks", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		cutoff := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+		s.dbMock.ExpectExec(purgeQuery).
+			WithArgs(cutoff, 1000).
+			WillReturnResult(sqlmock.NewResult(0, 1000))
+		s.dbMock.ExpectExec(purgeQuery).
+			WithArgs(cutoff, 1000).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+
+		deleted, err := s.repository.Purge(context.Background(), cutoff)
+		s.NoError(err)
+		s.Equal(int64(1005), deleted)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should return deleted count and error if db delete returns error", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		expectedError := errors.New("test error")
+		s.dbMock.ExpectExec(purgeQuery).WillReturnResult(sqlmock.NewResult(0, 1000))
+		s.dbMock.ExpectExec(purgeQuery).WillReturnError(expectedError)
+
+		deleted, err := s.repository.Purge(context.Background(), time.Now())
+		s.ErrorIs(err, expectedError)
+		s.Equal(int64(1000), deleted)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should purge periodically using retention", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		now := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+		audit.TimeNow = func() time.Time { return now }
+		defer func() { audit.TimeNow = time.Now }()
+
+		s.dbMock.ExpectExec(purgeQuery).
+			WithArgs(now.Add(-24*time.Hour), 1000).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			s.repository.PurgePeriodically(ctx, 24*time.Hour, 10*time.Millisecond, nil)
+			close(done)
+		}()
+
+		s.Eventually(func() bool {
+			return s.dbMock.ExpectationsWereMet() == nil
+		}, time.Second, 5*time.Millisecond)
+		cancel()
+		<-done
+	})
+}
+
+func (s *PostgresRepositoryTestSuite) TestWithTableName() {
+	newRepository := func() *repositories.PostgresRepository {
+		return repositories.NewPostgresRepository(s.gormDB, repositories.WithTableName("guardian_audit_logs"))
+	}
+
+	s.Run("should migrate the table", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE "guardian_audit_logs" ("timestamp" timestamptz,"action" text,"actor" text,"severity" text NOT NULL DEFAULT 'info',"data" JSONB,"data_schema" text,"data_version" bigint,"metadata" JSONB)`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX "idx_guardian_audit_logs_severity" ON "guardian_audit_logs" ("severity")`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := newRepository().Init(context.Background())
+		s.NoError(err)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should insert into the table", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		s.dbMock.ExpectBegin()
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "guardian_audit_logs" ("timestamp","action","actor","severity","data","data_schema","data_version","metadata") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		s.dbMock.ExpectCommit()
+
+		err := newRepository().Insert(context.Background(), newLog())
+		s.NoError(err)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should list from the table", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		s.dbMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "guardian_audit_logs" WHERE "actor" = $1 ORDER BY "timestamp" DESC`)).
+			WithArgs("user@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"timestamp", "action", "actor"}))
+
+		logs, err := newRepository().List(context.Background(), audit.Filter{Actor: "user@example.com"})
+		s.NoError(err)
+		s.Empty(logs)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+
+	s.Run("should purge from the table", func() {
+		s.setupTest()
+		defer s.cleanupTest()
+
+		cutoff := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+		s.dbMock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "guardian_audit_logs" WHERE ctid IN (SELECT ctid FROM "guardian_audit_logs" WHERE "timestamp" < $1 LIMIT $2)`)).
+			WithArgs(cutoff, 1000).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		deleted, err := newRepository().Purge(context.Background(), cutoff)
+		s.NoError(err)
+		s.Equal(int64(3), deleted)
+		s.NoError(s.dbMock.ExpectationsWereMet())
+	})
+}
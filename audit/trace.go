@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// TraceIDKey is the metadata key the trace id
+// of the context is recorded under by Service.Log
+var TraceIDKey = "trace_id"
+
+type traceIDContextKey struct{}
+
+// WithTraceID returns a context carrying the trace id recorded in the metadata by Service.Log
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey{}, traceID)
+}
+
+// WithTraceIDExtractor replaces the default extractor of the trace id
+// recorded in the metadata, e.g. to read the trace id of the otel span:
+//   audit.WithTraceIDExtractor(func(ctx context.Context) string {
+//       if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+//           return sc.TraceID().String()
+//       }
+//       return ""
+//   })
+func WithTraceIDExtractor(fn func(context.Context) string) AuditOption {
+	return func(s *Service) {
+		s.traceIDExtractor = fn
+	}
+}
+
+// defaultTraceIDExtractor returns the trace id set with WithTraceID, or
+// the one of the W3C traceparent in the incoming gRPC metadata
+func defaultTraceIDExtractor(ctx context.Context) string {
+	if traceID, ok := ctx.Value(traceIDContextKey{}).(string); ok {
+		return traceID
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get("traceparent")
+	if len(values) == 0 {
+		return ""
+	}
+	return traceIDFromTraceparent(values[0])
+}
+
+// traceIDFromTraceparent returns the trace id of a traceparent in the
+// `version-traceid-parentid-flags` format, empty if it is invalid
+func traceIDFromTraceparent(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || parts[1] == strings.Repeat("0", 32) {
+		return ""
+	}
+	for _, c := range parts[1] {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return ""
+		}
+	}
+	return parts[1]
+}
+
+// withTraceID returns a copy of the metadata with the trace id,
+// a trace id already in the metadata is kept
+func withTraceID(md interface{}, traceID string) interface{} {
+	if md == nil {
+		return map[string]interface{}{TraceIDKey: traceID}
+	}
+
+	mapMd, ok := md.(map[string]interface{})
+	if !ok {
+		return md
+	}
+	if _, ok := mapMd[TraceIDKey]; ok {
+		return md
+	}
+
+	newMd := make(map[string]interface{}, len(mapMd)+1)
+	for k, v := range mapMd {
+		newMd[k] = v
+	}
+	newMd[TraceIDKey] = traceID
+	return newMd
+}
@@ -0,0 +1,45 @@
+package cmdx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// AddAlias adds a hidden command to parent under the old name of
+// a renamed command, so scripts using the old name keep working.
+// The alias prints a deprecation warning to stderr and runs the
+// target command with the same args and flags.
+func AddAlias(parent *cobra.Command, oldName string, target *cobra.Command) *cobra.Command {
+	alias := &cobra.Command{
+		Use:               oldName + strings.TrimPrefix(target.Use, target.Name()),
+		Short:             target.Short,
+		Hidden:            true,
+		Args:              target.Args,
+		PersistentPreRun:  target.PersistentPreRun,
+		PersistentPreRunE: target.PersistentPreRunE,
+		PreRun:            target.PreRun,
+		PreRunE:           target.PreRunE,
+		PostRun:           target.PostRun,
+		PostRunE:          target.PostRunE,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %q is deprecated, use %q instead\n",
+				cmd.CommandPath(), target.CommandPath())
+
+			if target.RunE != nil {
+				return target.RunE(cmd, args)
+			}
+			if target.Run != nil {
+				target.Run(cmd, args)
+				return nil
+			}
+			return cmd.Help()
+		},
+	}
+	// the flags are shared so the target reads the values set on the alias
+	alias.Flags().AddFlagSet(target.Flags())
+
+	parent.AddCommand(alias)
+	return alias
+}
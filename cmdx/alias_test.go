@@ -0,0 +1,67 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAlias(t *testing.T) {
+	newRoot := func(ran *[]string) *cobra.Command {
+		root := &cobra.Command{Use: "stencil", SilenceUsage: true}
+		namespace := &cobra.Command{Use: "namespace"}
+		var format string
+		create := &cobra.Command{
+			Use:   "create <name>",
+			Short: "Create a namespace",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				*ran = append(*ran, args[0], format)
+				return nil
+			},
+		}
+		create.Flags().StringVarP(&format, "format", "f", "", "Schema format")
+		namespace.AddCommand(create)
+		root.AddCommand(namespace)
+
+		cmdx.AddAlias(namespace, "new", create)
+		return root
+	}
+
+	t.Run("should run the target command with args and flags", func(t *testing.T) {
+		var ran []string
+		var stdout, stderr bytes.Buffer
+		root := newRoot(&ran)
+		root.SetOut(&stdout)
+		root.SetErr(&stderr)
+		root.SetArgs([]string{"namespace", "new", "quickstart", "--format", "protobuf"})
+
+		assert.NoError(t, root.Execute())
+		assert.Equal(t, []string{"quickstart", "protobuf"}, ran)
+		assert.Equal(t, "Warning: \"stencil namespace new\" is deprecated, use \"stencil namespace create\" instead\n", stderr.String())
+		assert.Empty(t, stdout.String())
+	})
+
+	t.Run("should validate args like the target command", func(t *testing.T) {
+		var ran []string
+		root := newRoot(&ran)
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs([]string{"namespace", "new"})
+
+		assert.Error(t, root.Execute())
+		assert.Empty(t, ran)
+	})
+
+	t.Run("should hide the alias", func(t *testing.T) {
+		var ran []string
+		root := newRoot(&ran)
+		alias, _, err := root.Find([]string{"namespace", "new"})
+		assert.NoError(t, err)
+		assert.Equal(t, "new <name>", alias.Use)
+		assert.True(t, alias.Hidden)
+	})
+}
@@ -0,0 +1,83 @@
+package cmdx
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// AuthInfo is the auth info stored under the `auth` key
+// of the client config by the login command of the app.
+type AuthInfo struct {
+	Host  string `yaml:"host" mapstructure:"host"`
+	User  string `yaml:"user" mapstructure:"user"`
+	Token string `yaml:"token" mapstructure:"token"`
+}
+
+// LoggedIn returns true if a token is stored.
+func (a AuthInfo) LoggedIn() bool {
+	return a.Token != ""
+}
+
+// Auth returns the auth info stored in the config file,
+// it is empty if the config file does not exist.
+func (c *Config) Auth() (AuthInfo, error) {
+	var cfg struct {
+		Auth AuthInfo `yaml:"auth"`
+	}
+
+	data, err := ioutil.ReadFile(c.filename)
+	if os.IsNotExist(err) {
+		return cfg.Auth, nil
+	} else if err != nil {
+		return cfg.Auth, err
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg.Auth, fmt.Errorf("invalid config file %s: %w", c.filename, err)
+	}
+	return cfg.Auth, nil
+}
+
+// SetAuthStatusCmd adds the `auth status` command to the root command
+// which prints the user and host the client is logged in to, using
+// the auth info stored in the client config.
+func SetAuthStatusCmd(root *cobra.Command, cfg *Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "status",
+		Aliases: []string{"whoami"},
+		Short:   "Show the current login status",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			auth, err := cfg.Auth()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			switch {
+			case !auth.LoggedIn():
+				fmt.Fprintln(out, "Not logged in")
+			case auth.User == "":
+				fmt.Fprintf(out, "Logged in to %s\n", auth.Host)
+			default:
+				fmt.Fprintf(out, "Logged in to %s as %s\n", auth.Host, auth.User)
+			}
+			return nil
+		},
+	}
+
+	authCmd, _, err := root.Find([]string{"auth"})
+	if err != nil || authCmd == root {
+		authCmd = &cobra.Command{
+			Use:   "auth",
+			Short: "Manage authentication",
+		}
+		root.AddCommand(authCmd)
+	}
+	authCmd.AddCommand(cmd)
+	return cmd
+}
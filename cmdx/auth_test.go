@@ -0,0 +1,84 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAuthStatusCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmdx")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer setenv(t, cmdx.ODPF_CONFIG_DIR, dir)()
+	cfg := cmdx.NewConfig("stencil")
+
+	execute := func(args ...string) (string, error) {
+		root := &cobra.Command{Use: "stencil", SilenceErrors: true, SilenceUsage: true}
+		cmdx.SetAuthStatusCmd(root, cfg)
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	t.Run("should print not logged in without config file", func(t *testing.T) {
+		out, err := execute("auth", "status")
+		assert.NoError(t, err)
+		assert.Equal(t, "Not logged in\n", out)
+	})
+
+	t.Run("should print not logged in without token", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(cfg.File(), []byte("host: localhost:8080\n"), 0600))
+
+		out, err := execute("auth", "status")
+		assert.NoError(t, err)
+		assert.Equal(t, "Not logged in\n", out)
+	})
+
+	t.Run("should print user and host", func(t *testing.T) {
+		assert.NoError(t, cfg.Save(map[string]interface{}{
+			"host": "localhost:8080",
+			"auth": cmdx.AuthInfo{Host: "stencil.example.com", User: "user@example.com", Token: "s3cret"},
+		}))
+
+		out, err := execute("auth", "whoami")
+		assert.NoError(t, err)
+		assert.Equal(t, "Logged in to stencil.example.com as user@example.com\n", out)
+	})
+
+	t.Run("should print host without user", func(t *testing.T) {
+		assert.NoError(t, cfg.Save(map[string]interface{}{
+			"auth": cmdx.AuthInfo{Host: "stencil.example.com", Token: "s3cret"},
+		}))
+
+		out, err := execute("auth", "status")
+		assert.NoError(t, err)
+		assert.Equal(t, "Logged in to stencil.example.com\n", out)
+	})
+
+	t.Run("should return error for invalid config file", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(cfg.File(), []byte("auth: [\n"), 0600))
+
+		_, err := execute("auth", "status")
+		assert.Error(t, err)
+	})
+
+	t.Run("should add status to existing auth command", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil"}
+		authCmd := &cobra.Command{Use: "auth"}
+		root.AddCommand(authCmd)
+
+		cmd := cmdx.SetAuthStatusCmd(root, cfg)
+		assert.Equal(t, authCmd, cmd.Parent())
+		assert.Len(t, root.Commands(), 1)
+	})
+}
@@ -2,6 +2,7 @@ package cmdx
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/mcuadros/go-defaults"
 	"github.com/odpf/salt/config"
+	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,6 +25,12 @@ const (
 // It is used to load and save a config file
 // for command line clients.
 func SetConfig(app string) *Config {
+	return NewConfig(app)
+}
+
+// NewConfig returns the client config for the app stored
+// as <app>.yml in the per user odpf config directory.
+func NewConfig(app string) *Config {
 	return &Config{
 		filename: configFile(app),
 	}
@@ -76,6 +84,67 @@ func (c *Config) Load(cfg interface{}) error {
 	return nil
 }
 
+// Save writes the config as yaml to the config file,
+// creating the config directory if needed.
+func (c *Config) Save(cfg interface{}) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.filename), 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.filename, data, 0600)
+}
+
+// SetConfigInitCmd adds the `config init` command to the root command
+// which writes a starter config file for the app in the user config
+// directory, with the defaults and descriptions of the sample struct.
+// It refuses to overwrite an existing config file unless `--force` is set.
+func SetConfigInitCmd(root *cobra.Command, sample interface{}) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create a config file with the defaults",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := config.Sample(sample)
+			if err != nil {
+				return err
+			}
+
+			c := NewConfig(root.Name())
+			if fileExist(c.File()) && !force {
+				return NewUserError("config file already exists at %s, use --force to overwrite it", c.File())
+			}
+			if err := os.MkdirAll(filepath.Dir(c.File()), 0700); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(c.File(), data, 0600); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Config file created at %s\n", c.File())
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite the existing config file")
+
+	configCmd, _, err := root.Find([]string{"config"})
+	if err != nil || configCmd == root {
+		configCmd = &cobra.Command{
+			Use:   "config",
+			Short: "Manage the client config",
+		}
+		root.AddCommand(configCmd)
+	}
+	configCmd.AddCommand(cmd)
+	return cmd
+}
+
 func configFile(app string) string {
 	file := app + ".yml"
 	return filepath.Join(configDir("odpf"), file)
@@ -1,9 +1,14 @@
 package cmdx_test
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,3 +32,105 @@ func TestInit(t *testing.T) {
 		assert.Equal(t, "localhost", cliconfig.Host)
 	})
 }
+
+func TestSave(t *testing.T) {
+	t.Run("should save and load config from user config dir", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "cmdx")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		defer setenv(t, cmdx.ODPF_CONFIG_DIR, "")()
+		defer setenv(t, cmdx.XDG_CONFIG_HOME, dir)()
+
+		c := cmdx.NewConfig("stencil")
+		assert.Equal(t, filepath.Join(dir, "odpf", "stencil.yml"), c.File())
+
+		err = c.Save(&TestConfig{Host: "example.com"})
+		assert.NoError(t, err)
+
+		loaded := &TestConfig{}
+		err = c.Load(loaded)
+		assert.NoError(t, err)
+		assert.Equal(t, "example.com", loaded.Host)
+	})
+}
+
+func setenv(t *testing.T, key, value string) func() {
+	t.Helper()
+
+	prev, ok := os.LookupEnv(key)
+	assert.NoError(t, os.Setenv(key, value))
+	return func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+type sampleConfig struct {
+	Host string `mapstructure:"host" desc:"Server host" default:"localhost:8080"`
+}
+
+func TestSetConfigInitCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmdx")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer setenv(t, cmdx.ODPF_CONFIG_DIR, dir)()
+	file := filepath.Join(dir, "stencil.yml")
+
+	execute := func(args ...string) (string, error) {
+		root := &cobra.Command{Use: "stencil", SilenceErrors: true, SilenceUsage: true}
+		cmdx.SetConfigInitCmd(root, &sampleConfig{})
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(append([]string{"config", "init"}, args...))
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	t.Run("should write config file with defaults", func(t *testing.T) {
+		out, err := execute()
+		assert.NoError(t, err)
+		assert.Equal(t, "Config file created at "+file+"\n", out)
+
+		data, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "# Server host\nhost: localhost:8080\n", string(data))
+	})
+
+	t.Run("should not overwrite existing config file", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(file, []byte("host: example.com\n"), 0600))
+
+		_, err := execute()
+		assert.EqualError(t, err, "config file already exists at "+file+", use --force to overwrite it")
+
+		data, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "host: example.com\n", string(data))
+	})
+
+	t.Run("should overwrite existing config file with force", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(file, []byte("host: example.com\n"), 0600))
+
+		_, err := execute("--force")
+		assert.NoError(t, err)
+
+		data, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "# Server host\nhost: localhost:8080\n", string(data))
+	})
+
+	t.Run("should add init to existing config command", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil"}
+		configCmd := &cobra.Command{Use: "config"}
+		root.AddCommand(configCmd)
+
+		cmd := cmdx.SetConfigInitCmd(root, &sampleConfig{})
+		assert.Equal(t, configCmd, cmd.Parent())
+		assert.Len(t, root.Commands(), 1)
+	})
+}
@@ -0,0 +1,27 @@
+package cmdx
+
+import (
+	"github.com/odpf/salt/config"
+	"github.com/spf13/cobra"
+)
+
+// RegisterConfigFlags adds the persistent `--config` and `--config-type`
+// flags to the command to set the config file read by the loader
+// returned by ConfigLoaderFromFlags.
+func RegisterConfigFlags(root *cobra.Command) {
+	root.PersistentFlags().String("config", "", "Config file path")
+	root.PersistentFlags().String("config-type", "", "Config file type, e.g. yaml or json, detected from the file extension by default")
+}
+
+// ConfigLoaderFromFlags returns a config loader with the options, reading
+// the config file set with the `--config` flag in the type set with the
+// `--config-type` flag. The flags take precedence over the options.
+func ConfigLoaderFromFlags(cmd *cobra.Command, opts ...config.LoaderOption) *config.Loader {
+	if file, _ := cmd.Flags().GetString("config"); file != "" {
+		opts = append(opts, config.WithFile(file))
+	}
+	if typ, _ := cmd.Flags().GetString("config-type"); typ != "" {
+		opts = append(opts, config.WithType(typ))
+	}
+	return config.NewLoader(opts...)
+}
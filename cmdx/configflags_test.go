@@ -0,0 +1,76 @@
+package cmdx_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/odpf/salt/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type serverConfig struct {
+	Host string `mapstructure:"host" default:"localhost"`
+	Port int    `mapstructure:"port" default:"8080"`
+}
+
+func TestConfigLoaderFromFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmdx")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// load executes a subcommand of a root with the config flags
+	// and loads the config with the loader of the subcommand
+	load := func(t *testing.T, args []string, opts ...config.LoaderOption) (serverConfig, error) {
+		t.Helper()
+
+		var cfg serverConfig
+		var loadErr error
+		root := &cobra.Command{Use: "stencil"}
+		cmdx.RegisterConfigFlags(root)
+		root.AddCommand(&cobra.Command{
+			Use: "serve",
+			Run: func(cmd *cobra.Command, args []string) {
+				loadErr = cmdx.ConfigLoaderFromFlags(cmd, opts...).Load(&cfg)
+			},
+		})
+		root.SetArgs(append([]string{"serve"}, args...))
+		assert.NoError(t, root.Execute())
+		return cfg, loadErr
+	}
+
+	t.Run("should load config file set with flag", func(t *testing.T) {
+		file := filepath.Join(dir, "custom.yaml")
+		assert.NoError(t, ioutil.WriteFile(file, []byte("port: 9000\n"), 0600))
+
+		cfg, err := load(t, []string{"--config", file})
+		assert.NoError(t, err)
+		assert.Equal(t, serverConfig{Host: "localhost", Port: 9000}, cfg)
+	})
+
+	t.Run("should load config file in type set with flag", func(t *testing.T) {
+		file := filepath.Join(dir, "stencil.conf")
+		assert.NoError(t, ioutil.WriteFile(file, []byte(`{"host": "example.com"}`), 0600))
+
+		cfg, err := load(t, []string{"--config", file, "--config-type", "json"})
+		assert.NoError(t, err)
+		assert.Equal(t, serverConfig{Host: "example.com", Port: 8080}, cfg)
+	})
+
+	t.Run("should return error for unsupported type", func(t *testing.T) {
+		_, err := load(t, []string{"--config-type", "xml"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported config type "xml"`)
+	})
+
+	t.Run("should use options without flags", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte("port: 9001\n"), 0600))
+
+		cfg, err := load(t, nil, config.WithPath(dir))
+		assert.NoError(t, err)
+		assert.Equal(t, serverConfig{Host: "localhost", Port: 9001}, cfg)
+	})
+}
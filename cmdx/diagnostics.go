@@ -0,0 +1,76 @@
+package cmdx
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/odpf/salt/config"
+	"github.com/spf13/cobra"
+)
+
+// SetDiagnosticsCmd creates an env command printing the version of
+// the root command, OS/arch, the environment variables the config is
+// loaded from and the effective config, e.g. to attach to bug reports.
+// Env variables are prefixed with envPrefix, the prefix the config is
+// loaded with, e.g. `config.WithEnvPrefix`, unless it is empty.
+// Values of fields tagged `secret:"true"` or `source:"env"` are masked.
+func SetDiagnosticsCmd(root *cobra.Command, cfg interface{}, envPrefix string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "env",
+		Aliases: []string{"diagnostics"},
+		Short:   "Print diagnostics for bug reports",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			conf, err := config.Redacted(cfg)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Version:    %s\n", root.Version)
+			fmt.Fprintf(out, "OS/Arch:    %s/%s\n", runtime.GOOS, runtime.GOARCH)
+			fmt.Fprintf(out, "Go version: %s\n", runtime.Version())
+
+			env := diagnosticsEnv(cfg, envPrefix)
+			if len(env) == 0 {
+				env = []string{"none set"}
+			}
+			fmt.Fprintf(out, "\nEnvironment:\n%s\n", indent(strings.Join(env, "\n"), "  "))
+			fmt.Fprintf(out, "\nConfig:\n%s\n", indent(strings.TrimRight(string(conf), "\n"), "  "))
+			return nil
+		},
+	}
+}
+
+// diagnosticsEnv returns the set env variables of the config
+// struct fields as `NAME=value`, with secrets masked
+func diagnosticsEnv(cfg interface{}, prefix string) []string {
+	t := reflect.TypeOf(cfg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var env []string
+	for _, v := range envVars(t, "") {
+		name := v.name
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		name = strings.ToUpper(name)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if v.secret && value != "" {
+			value = "*****"
+		}
+		env = append(env, name+"="+value)
+	}
+	return env
+}
@@ -0,0 +1,75 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type diagnosticsConfig struct {
+	Host  string `mapstructure:"host"`
+	Token string `mapstructure:"token" secret:"true"`
+}
+
+func TestSetDiagnosticsCmd(t *testing.T) {
+	t.Run("should print version, os, env and masked config", func(t *testing.T) {
+		defer setenv(t, "MY_CLI_TOKEN", "env-t0ken")()
+		defer setenv(t, "MY_CLI_HOST", "example.com")()
+
+		root := &cobra.Command{Use: "my-cli", Version: "v1.2.3"}
+		root.AddCommand(cmdx.SetDiagnosticsCmd(root, &diagnosticsConfig{Host: "example.com", Token: "t0ken"}, "MY_CLI"))
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs([]string{"env"})
+		assert.NoError(t, root.Execute())
+
+		assert.Equal(t, fmt.Sprintf(`Version:    v1.2.3
+OS/Arch:    %s/%s
+Go version: %s
+
+Environment:
+  MY_CLI_HOST=example.com
+  MY_CLI_TOKEN=*****
+
+Config:
+  host: example.com
+  token: '*****'
+`, runtime.GOOS, runtime.GOARCH, runtime.Version()), out.String())
+		assert.NotContains(t, out.String(), "t0ken")
+	})
+
+	t.Run("should print env variables with the given prefix", func(t *testing.T) {
+		defer setenv(t, "APP_HOST", "example.com")()
+		defer setenv(t, "MY_CLI_TOKEN", "env-t0ken")()
+
+		root := &cobra.Command{Use: "my-cli", Version: "v1.2.3"}
+		root.AddCommand(cmdx.SetDiagnosticsCmd(root, &diagnosticsConfig{}, "app"))
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs([]string{"env"})
+		assert.NoError(t, root.Execute())
+
+		assert.Contains(t, out.String(), "\nEnvironment:\n  APP_HOST=example.com\n\n")
+	})
+
+	t.Run("should print env variables without prefix", func(t *testing.T) {
+		defer setenv(t, "HOST", "example.com")()
+
+		root := &cobra.Command{Use: "my-cli", Version: "v1.2.3"}
+		root.AddCommand(cmdx.SetDiagnosticsCmd(root, &diagnosticsConfig{}, ""))
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs([]string{"env"})
+		assert.NoError(t, root.Execute())
+
+		assert.Contains(t, out.String(), "\nEnvironment:\n  HOST=example.com\n\n")
+	})
+}
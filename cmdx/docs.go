@@ -0,0 +1,102 @@
+package cmdx
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+)
+
+// SetDocsCmd creates a docs command which generates a
+// markdown file per command for documentation sites.
+func SetDocsCmd(root *cobra.Command) *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate markdown documentation",
+		Long: heredoc.Doc(`
+			Generate a markdown file per command with front matter,
+			ready to be served by static site generators like Hugo or Docusaurus.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := GenDocsTree(root, dir); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Documentation generated in %s\n", dir)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&dir, "dir", "d", "docs", "Directory to write the documentation to")
+	return cmd
+}
+
+// GenDocsTree generates a markdown file for the command and each
+// of its subcommands in the given directory, with front matter
+// and links to the subcommands. Files are named after the
+// command path, e.g. `app-sub.md`.
+func GenDocsTree(cmd *cobra.Command, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return genDocsTree(cmd, dir)
+}
+
+func genDocsTree(cmd *cobra.Command, dir string) error {
+	for _, c := range cmd.Commands() {
+		if c.Hidden {
+			continue
+		}
+		if err := genDocsTree(c, dir); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(filepath.Join(dir, docSlug(cmd)+".md"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	docRef(f, cmd)
+	return nil
+}
+
+func docRef(w io.Writer, cmd *cobra.Command) {
+	fmt.Fprintf(w, "---\ntitle: %s\nslug: %s\n---\n\n", cmd.CommandPath(), docSlug(cmd))
+
+	cmdDoc(w, cmd, 1)
+
+	if cmd.Long != "" {
+		fmt.Fprintf(w, "%s\n\n", strings.TrimSpace(cmd.Long))
+	}
+
+	if cmd.Example != "" {
+		fmt.Fprintf(w, "## Examples\n\n```\n%s\n```\n\n", strings.TrimSpace(cmd.Example))
+	}
+
+	var subcommands []string
+	for _, c := range cmd.Commands() {
+		if c.Hidden {
+			continue
+		}
+		subcommands = append(subcommands, fmt.Sprintf("- [%s](%s.md): %s", c.CommandPath(), docSlug(c), c.Short))
+	}
+	if len(subcommands) > 0 {
+		fmt.Fprintf(w, "## Subcommands\n\n%s\n\n", strings.Join(subcommands, "\n"))
+	}
+
+	if cmd.HasParent() {
+		p := cmd.Parent()
+		fmt.Fprintf(w, "## See also\n\n- [%s](%s.md): %s\n", p.CommandPath(), docSlug(p), p.Short)
+	}
+}
+
+func docSlug(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
@@ -0,0 +1,106 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenDocsTree(t *testing.T) {
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		namespace := &cobra.Command{Use: "namespace", Short: "Manage namespaces"}
+		create := &cobra.Command{
+			Use:     "create <name>",
+			Short:   "Create a namespace",
+			Long:    "Create a namespace in the schema registry.",
+			Example: "$ stencil namespace create my-namespace",
+			Run:     func(cmd *cobra.Command, args []string) {},
+		}
+		create.Flags().StringP("format", "f", "", "Schema format")
+		hidden := &cobra.Command{Use: "hidden", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}}
+		namespace.AddCommand(create)
+		root.AddCommand(namespace, hidden)
+		return root
+	}
+
+	readDoc := func(t *testing.T, dir, name string) string {
+		t.Helper()
+
+		doc, err := ioutil.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		return string(doc)
+	}
+
+	t.Run("should write a markdown file per command", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "cmdx")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		err = cmdx.GenDocsTree(newRoot(), dir)
+		assert.NoError(t, err)
+
+		files, err := ioutil.ReadDir(dir)
+		assert.NoError(t, err)
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		assert.Equal(t, []string{"stencil-namespace-create.md", "stencil-namespace.md", "stencil.md"}, names)
+	})
+
+	t.Run("should write front matter, reference and links", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "cmdx")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		err = cmdx.GenDocsTree(newRoot(), dir)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "---\n"+
+			"title: stencil namespace\n"+
+			"slug: stencil-namespace\n"+
+			"---\n\n"+
+			"# `stencil namespace`\n\n"+
+			"Manage namespaces\n\n"+
+			"## Subcommands\n\n"+
+			"- [stencil namespace create](stencil-namespace-create.md): Create a namespace\n\n"+
+			"## See also\n\n"+
+			"- [stencil](stencil.md): Schema registry\n", readDoc(t, dir, "stencil-namespace.md"))
+
+		doc := readDoc(t, dir, "stencil-namespace-create.md")
+		assert.Contains(t, doc, "---\ntitle: stencil namespace create\nslug: stencil-namespace-create\n---\n\n")
+		assert.Contains(t, doc, "# `stencil namespace create <name> [flags]`\n\nCreate a namespace\n\n")
+		assert.Contains(t, doc, "-f, --format string   Schema format\n")
+		assert.Contains(t, doc, "Create a namespace in the schema registry.\n\n")
+		assert.Contains(t, doc, "## Examples\n\n```\n$ stencil namespace create my-namespace\n```\n\n")
+		assert.Contains(t, doc, "## See also\n\n- [stencil namespace](stencil-namespace.md): Manage namespaces\n")
+		assert.NotContains(t, readDoc(t, dir, "stencil.md"), "hidden")
+	})
+}
+
+func TestSetDocsCmd(t *testing.T) {
+	t.Run("should generate docs in the given directory", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "cmdx")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		root.AddCommand(cmdx.SetDocsCmd(root))
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs([]string{"docs", "--dir", dir})
+		assert.NoError(t, root.Execute())
+
+		assert.FileExists(t, filepath.Join(dir, "stencil.md"))
+		assert.FileExists(t, filepath.Join(dir, "stencil-docs.md"))
+		assert.Equal(t, "Documentation generated in "+dir+"\n", out.String())
+	})
+}
@@ -0,0 +1,136 @@
+package cmdx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/odpf/salt/config"
+	"github.com/odpf/salt/printer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type envVar struct {
+	name   string
+	desc   string
+	secret bool
+}
+
+// SetEnvHelp lists the environment variables the config struct is
+// loaded from under ENVIRONMENT VARIABLES in the help of the command.
+// Keys are read from the mapstructure tags, descriptions from the
+// desc tags and defaults from the default tags of the fields.
+// For example, with the prefix APP:
+//     type Config struct {
+//         Port int `mapstructure:"port" desc:"Port to listen on" default:"8080"`
+//     }
+// is listed as:
+//     APP_PORT  Port to listen on (default 8080)
+func SetEnvHelp(cmd *cobra.Command, config interface{}, prefix string) {
+	t := reflect.TypeOf(config)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+
+	vars := envVars(t, "")
+	if len(vars) == 0 {
+		return
+	}
+
+	width := 0
+	for i, v := range vars {
+		if prefix != "" {
+			vars[i].name = prefix + "_" + v.name
+		}
+		vars[i].name = strings.ToUpper(vars[i].name)
+		if len(vars[i].name) > width {
+			width = len(vars[i].name)
+		}
+	}
+
+	var lines []string
+	for _, v := range vars {
+		line := rpad(v.name, width+1) + v.desc
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	setAnnotation(cmd, "help:environment", strings.Join(lines, "\n"))
+}
+
+// FlagEnvTable returns a table of the flags of the command with the
+// env variable setting the same config key and the default of the flag,
+// e.g. to document the env variables along with SetEnvHelp. The env
+// names are derived from the flag names as config.Loader does, the
+// dots of nested keys and the dashes are replaced with underscores.
+// For example, with the prefix APP:
+//     FLAG          ENV            DEFAULT
+//     --db.host     APP_DB_HOST    localhost
+//     --log-level   APP_LOG_LEVEL  info
+func FlagEnvTable(cmd *cobra.Command, envPrefix string) string {
+	var sb strings.Builder
+	table := printer.NewTable(&sb).Headers("FLAG", "ENV", "DEFAULT").MaxWidth(0)
+
+	replacer := strings.NewReplacer(".", "_", "-", "_")
+	rows := 0
+	addRow := func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		env := strings.ToUpper(replacer.Replace(f.Name))
+		if envPrefix != "" {
+			env = strings.ToUpper(envPrefix) + "_" + env
+		}
+		table.AddRow("--"+f.Name, env, f.DefValue)
+		rows++
+	}
+	cmd.LocalFlags().VisitAll(addRow)
+	cmd.InheritedFlags().VisitAll(addRow)
+	if rows == 0 {
+		return ""
+	}
+
+	_ = table.Render()
+	return sb.String()
+}
+
+// envVars returns the env variables of the struct fields in
+// order, nested structs are joined with an underscore
+func envVars(t reflect.Type, parent string) []envVar {
+	var vars []envVar
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		key := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+		if parent != "" {
+			key = parent + "_" + key
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			vars = append(vars, envVars(ft, key)...)
+			continue
+		}
+
+		desc := f.Tag.Get("desc")
+		if def, ok := f.Tag.Lookup("default"); ok {
+			desc = strings.TrimSpace(fmt.Sprintf("%s (default %s)", desc, def))
+		}
+		vars = append(vars, envVar{name: key, desc: desc, secret: config.IsSecret(f)})
+	}
+	return vars
+}
@@ -0,0 +1,97 @@
+package cmdx_test
+
+import (
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type dbConfig struct {
+	Host string `mapstructure:"host" desc:"Database host" default:"localhost"`
+	Pass string `mapstructure:"password" desc:"Database password"`
+}
+
+type appConfig struct {
+	Port     int      `mapstructure:"port" desc:"Port to listen on" default:"8080"`
+	DB       dbConfig `mapstructure:"db"`
+	LogLevel string   `mapstructure:"log_level"`
+	Ignored  string   `mapstructure:"-"`
+	internal string
+}
+
+func TestSetEnvHelp(t *testing.T) {
+	t.Run("should set environment help from struct tags", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "serve"}
+		cmdx.SetEnvHelp(cmd, &appConfig{}, "APP")
+
+		assert.Equal(t, ""+
+			"APP_PORT         Port to listen on (default 8080)\n"+
+			"APP_DB_HOST      Database host (default localhost)\n"+
+			"APP_DB_PASSWORD  Database password\n"+
+			"APP_LOG_LEVEL", cmd.Annotations["help:environment"])
+	})
+
+	t.Run("should not add prefix if empty", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "serve"}
+		cmdx.SetEnvHelp(cmd, dbConfig{}, "")
+
+		assert.Equal(t, ""+
+			"HOST      Database host (default localhost)\n"+
+			"PASSWORD  Database password", cmd.Annotations["help:environment"])
+	})
+
+	t.Run("should not set help if config is not a struct", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "serve"}
+		cmdx.SetEnvHelp(cmd, "config", "APP")
+
+		assert.NotContains(t, cmd.Annotations, "help:environment")
+	})
+}
+
+func TestFlagEnvTable(t *testing.T) {
+	newServeCmd := func() *cobra.Command {
+		root := &cobra.Command{Use: "stencil"}
+		root.PersistentFlags().String("log.level", "info", "Log level")
+		serve := &cobra.Command{Use: "serve", Run: func(cmd *cobra.Command, args []string) {}}
+		serve.Flags().IntP("port", "p", 8080, "Port to listen on")
+		serve.Flags().String("db.host", "", "Database host")
+		serve.Flags().String("token", "", "Hidden token")
+		_ = serve.Flags().MarkHidden("token")
+		root.AddCommand(serve)
+		return serve
+	}
+
+	t.Run("should map flags to env variables with their defaults", func(t *testing.T) {
+		table := cmdx.FlagEnvTable(newServeCmd(), "app")
+
+		assert.Equal(t, ""+
+			"FLAG         ENV            DEFAULT\n"+
+			"--db.host    APP_DB_HOST\n"+
+			"--port       APP_PORT       8080\n"+
+			"--log.level  APP_LOG_LEVEL  info\n", table)
+	})
+
+	t.Run("should derive env names without prefix", func(t *testing.T) {
+		table := cmdx.FlagEnvTable(newServeCmd(), "")
+
+		assert.Contains(t, table, "--db.host    DB_HOST\n")
+		assert.NotContains(t, table, "token")
+	})
+
+	t.Run("should replace dashes in env names", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "serve"}
+		cmd.Flags().String("log-level", "info", "Log level")
+		cmd.Flags().String("db.max-conns", "10", "Max connections")
+
+		table := cmdx.FlagEnvTable(cmd, "app")
+
+		assert.Contains(t, table, "--db.max-conns  APP_DB_MAX_CONNS  10\n")
+		assert.Contains(t, table, "--log-level     APP_LOG_LEVEL     info\n")
+	})
+
+	t.Run("should return empty table for command without flags", func(t *testing.T) {
+		assert.Empty(t, cmdx.FlagEnvTable(&cobra.Command{Use: "stencil"}, "app"))
+	})
+}
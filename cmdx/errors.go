@@ -0,0 +1,96 @@
+package cmdx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by Execute.
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUserError   = 2
+	ExitConfigError = 3
+)
+
+// UserError is an error caused by the user input, e.g. invalid
+// arguments or flags. It is printed without the stack trace.
+type UserError struct {
+	Err error
+}
+
+// NewUserError returns a UserError with the formatted message.
+func NewUserError(format string, args ...interface{}) *UserError {
+	return &UserError{Err: fmt.Errorf(format, args...)}
+}
+
+func (e *UserError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UserError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError is an error caused by missing or invalid client config.
+type ConfigError struct {
+	Err error
+}
+
+// NewConfigError returns a ConfigError with the formatted message.
+func NewConfigError(format string, args ...interface{}) *ConfigError {
+	return &ConfigError{Err: fmt.Errorf(format, args...)}
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Execute runs the root command and returns the exit code for the
+// returned error. Errors are printed without the usage, and with
+// their details (e.g. stack trace) when `--verbose` flag is set.
+// A plugin which fails exits with its own exit code, see EnablePlugins.
+// It is expected to be used as `os.Exit(cmdx.Execute(root))`.
+func Execute(root *cobra.Command) int {
+	root.SilenceUsage = true
+	root.SilenceErrors = true
+	if root.PersistentFlags().Lookup("verbose") == nil {
+		root.PersistentFlags().Bool("verbose", false, "Show error details")
+	}
+
+	cmd, err := root.ExecuteC()
+	if err == nil {
+		return ExitOK
+	}
+
+	var pluginErr *PluginExitError
+	if errors.As(err, &pluginErr) {
+		return pluginErr.ExitCode()
+	}
+
+	out := root.ErrOrStderr()
+	fmt.Fprintf(out, "Error: %s\n", err)
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		fmt.Fprintf(out, "\n%+v\n", err)
+	}
+
+	var (
+		userErr   *UserError
+		configErr *ConfigError
+	)
+	switch {
+	case errors.As(err, &configErr):
+		return ExitConfigError
+	case errors.As(err, &userErr) || IsCmdErr(err):
+		fmt.Fprintf(out, "Run '%s --help' for usage.\n", cmd.CommandPath())
+		return ExitUserError
+	default:
+		return ExitError
+	}
+}
@@ -0,0 +1,76 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute(t *testing.T) {
+	execute := func(runErr error, args ...string) (int, string, string) {
+		root := &cobra.Command{Use: "stencil"}
+		root.AddCommand(&cobra.Command{
+			Use: "create",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runErr
+			},
+		})
+
+		var out, errOut bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&errOut)
+		root.SetArgs(args)
+		code := cmdx.Execute(root)
+		return code, out.String(), errOut.String()
+	}
+
+	t.Run("should return ok exit code on success", func(t *testing.T) {
+		code, _, errOut := execute(nil, "create")
+
+		assert.Equal(t, cmdx.ExitOK, code)
+		assert.Empty(t, errOut)
+	})
+
+	t.Run("should return user error exit code without printing usage", func(t *testing.T) {
+		code, out, errOut := execute(cmdx.NewUserError("namespace %q is invalid", "foo"), "create")
+
+		assert.Equal(t, cmdx.ExitUserError, code)
+		assert.Empty(t, out)
+		assert.Equal(t, "Error: namespace \"foo\" is invalid\nRun 'stencil create --help' for usage.\n", errOut)
+	})
+
+	t.Run("should return user error exit code for unknown flag", func(t *testing.T) {
+		code, _, errOut := execute(nil, "create", "--unknown")
+
+		assert.Equal(t, cmdx.ExitUserError, code)
+		assert.NotContains(t, errOut, "Usage:")
+	})
+
+	t.Run("should return config error exit code", func(t *testing.T) {
+		code, _, errOut := execute(cmdx.NewConfigError("host is not set"), "create")
+
+		assert.Equal(t, cmdx.ExitConfigError, code)
+		assert.Equal(t, "Error: host is not set\n", errOut)
+	})
+
+	t.Run("should return error exit code for other errors", func(t *testing.T) {
+		code, _, errOut := execute(errors.New("connection refused"), "create")
+
+		assert.Equal(t, cmdx.ExitError, code)
+		assert.Equal(t, "Error: connection refused\n", errOut)
+	})
+
+	t.Run("should print error details when verbose", func(t *testing.T) {
+		code, _, errOut := execute(pkgerrors.New("connection refused"), "create", "--verbose")
+
+		assert.Equal(t, cmdx.ExitError, code)
+		assert.Contains(t, errOut, "Error: connection refused\n\nconnection refused\n")
+		assert.Contains(t, errOut, "errors_test.go")
+	})
+}
@@ -2,25 +2,121 @@ package cmdx
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
+// Keys of the built-in sections of the help, other
+// sections are read from the annotations of the command.
+const (
+	HelpDescription        = "description"
+	HelpUsage              = "usage"
+	HelpCoreCommands       = "commands:core"
+	HelpOtherCommands      = "commands:other"
+	HelpAdditionalCommands = "commands:additional"
+	HelpFlags              = "flags"
+	HelpInheritedFlags     = "inherited-flags"
+	HelpExamples           = "examples"
+)
+
+// HelpSection is a section of the help. The key is either one of
+// the built-in section keys or the key of the command annotation
+// holding the body of the section, e.g. `help:environment`. The
+// `%s` in the title of HelpOtherCommands is replaced by the group
+// name, which is appended to the title if there is none.
+type HelpSection struct {
+	Key   string
+	Title string
+}
+
+// HelpConfig sets the sections of the help in the order
+// they are printed, sections without a body are skipped.
+type HelpConfig struct {
+	Sections []HelpSection
+}
+
+// DefaultHelpConfig returns the sections of the help used by SetHelp
+// by default, e.g. to insert a section or rename one of them.
+func DefaultHelpConfig() HelpConfig {
+	return HelpConfig{
+		Sections: []HelpSection{
+			{Key: HelpDescription},
+			{Key: HelpUsage, Title: "USAGE"},
+			{Key: HelpCoreCommands, Title: "CORE COMMANDS"},
+			{Key: HelpOtherCommands, Title: "%s COMMANDS"},
+			{Key: HelpAdditionalCommands, Title: "ADDITIONAL COMMANDS"},
+			{Key: HelpFlags, Title: "FLAGS"},
+			{Key: HelpInheritedFlags, Title: "INHERITED FLAGS"},
+			{Key: "help:arguments", Title: "ARGUMENTS"},
+			{Key: HelpExamples, Title: "EXAMPLES"},
+			{Key: "help:environment", Title: "ENVIRONMENT VARIABLES"},
+			{Key: "help:learn", Title: "LEARN MORE"},
+			{Key: "help:feedback", Title: "FEEDBACK"},
+		},
+	}
+}
+
 // SetHelp sets a custom help and usage function.
 // It allows to group commands in different sections
-// based on cobra commands annotations.
-func SetHelp(cmd *cobra.Command) {
+// based on cobra commands annotations. The order and
+// titles of the sections can be set with a HelpConfig,
+// DefaultHelpConfig is used if none is given.
+func SetHelp(cmd *cobra.Command, config ...HelpConfig) {
+	cfg := DefaultHelpConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
 	cmd.PersistentFlags().Bool("help", false, "Show help for command")
 
 	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
-		rootHelpFunc(cmd, args)
+		rootHelpFunc(cmd, args, cfg)
 	})
 	cmd.SetUsageFunc(rootUsageFunc)
 	cmd.SetFlagErrorFunc(rootFlagErrorFunc)
 }
 
+// AddCoreCommand adds the command to the root command
+// and lists it under CORE COMMANDS in help.
+func AddCoreCommand(root *cobra.Command, cmd *cobra.Command) {
+	setAnnotation(cmd, "group:core", "true")
+	root.AddCommand(cmd)
+}
+
+// AddOtherCommand adds the command to the root command
+// and lists it under <GROUP> COMMANDS in help.
+func AddOtherCommand(root *cobra.Command, group string, cmd *cobra.Command) {
+	setAnnotation(cmd, "group:other", group)
+	root.AddCommand(cmd)
+}
+
+// AddAdditionalCommand adds the command to the root command
+// and lists it under ADDITIONAL COMMANDS in help.
+func AddAdditionalCommand(root *cobra.Command, cmd *cobra.Command) {
+	delete(cmd.Annotations, "group:core")
+	delete(cmd.Annotations, "group:other")
+	root.AddCommand(cmd)
+}
+
+func setAnnotation(cmd *cobra.Command, key, value string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[key] = value
+}
+
+// otherCommandsTitle returns the title of the group of other commands,
+// the title is not a format so that a `%` in it is printed as is
+func otherCommandsTitle(title, group string) string {
+	if !strings.Contains(title, "%s") {
+		return strings.TrimSpace(title + " " + group)
+	}
+	return strings.Replace(title, "%s", group, 1)
+}
+
 func rootUsageFunc(command *cobra.Command) error {
 	command.Printf("Usage:  %s", command.UseLine())
 
@@ -48,15 +144,19 @@ func rootFlagErrorFunc(cmd *cobra.Command, err error) error {
 	if err == pflag.ErrHelp {
 		return err
 	}
-	return err
+	return &UserError{Err: err}
 }
 
-func rootHelpFunc(command *cobra.Command, args []string) {
+func rootHelpFunc(command *cobra.Command, args []string, config HelpConfig) {
 	if isRootCmd(command.Parent()) && len(args) >= 2 && args[1] != "--help" && args[1] != "-h" {
 		nestedSuggestFunc(command, args[1])
 		return
 	}
 
+	if prefix, ok := command.Annotations["plugins:prefix"]; ok {
+		addPluginCommands(command, prefix)
+	}
+
 	coreCommands := []string{}
 	otherCommands := map[string][]string{}
 	additionalCommands := []string{}
@@ -97,54 +197,45 @@ func rootHelpFunc(command *cobra.Command, args []string) {
 	}
 
 	helpEntries := []helpEntry{}
-	if text != "" {
-		helpEntries = append(helpEntries, helpEntry{"", text})
-	}
-
-	helpEntries = append(helpEntries, helpEntry{"USAGE", command.UseLine()})
-
-	if len(coreCommands) > 0 {
-		helpEntries = append(helpEntries, helpEntry{"CORE COMMANDS", strings.Join(coreCommands, "\n")})
-	}
-
-	for name, cmds := range otherCommands {
-		if len(cmds) > 0 {
-			helpEntries = append(helpEntries, helpEntry{strings.ToUpper(name) + " COMMANDS", strings.Join(cmds, "\n")})
+	for _, section := range config.Sections {
+		var body string
+		switch section.Key {
+		case HelpDescription:
+			body = text
+		case HelpUsage:
+			body = command.UseLine()
+		case HelpCoreCommands:
+			body = strings.Join(coreCommands, "\n")
+		case HelpOtherCommands:
+			groups := make([]string, 0, len(otherCommands))
+			for name := range otherCommands {
+				groups = append(groups, name)
+			}
+			sort.Strings(groups)
+			for _, name := range groups {
+				title := otherCommandsTitle(section.Title, strings.ToUpper(name))
+				helpEntries = append(helpEntries, helpEntry{title, strings.Join(otherCommands[name], "\n")})
+			}
+			continue
+		case HelpAdditionalCommands:
+			body = strings.Join(additionalCommands, "\n")
+		case HelpFlags:
+			if flagUsages := command.LocalFlags().FlagUsages(); flagUsages != "" {
+				body = dedent(flagUsages)
+			}
+		case HelpInheritedFlags:
+			if flagUsages := command.InheritedFlags().FlagUsages(); flagUsages != "" {
+				body = dedent(flagUsages)
+			}
+		case HelpExamples:
+			body = command.Example
+		default:
+			body = command.Annotations[section.Key]
 		}
-	}
-
-	if len(additionalCommands) > 0 {
-		helpEntries = append(helpEntries, helpEntry{"ADDITIONAL COMMANDS", strings.Join(additionalCommands, "\n")})
-	}
-
-	flagUsages := command.LocalFlags().FlagUsages()
-	if flagUsages != "" {
-		helpEntries = append(helpEntries, helpEntry{"FLAGS", dedent(flagUsages)})
-	}
-
-	inheritedFlagUsages := command.InheritedFlags().FlagUsages()
-	if inheritedFlagUsages != "" {
-		helpEntries = append(helpEntries, helpEntry{"INHERITED FLAGS", dedent(inheritedFlagUsages)})
-	}
 
-	if _, ok := command.Annotations["help:arguments"]; ok {
-		helpEntries = append(helpEntries, helpEntry{"ARGUMENTS", command.Annotations["help:arguments"]})
-	}
-
-	if command.Example != "" {
-		helpEntries = append(helpEntries, helpEntry{"EXAMPLES", command.Example})
-	}
-
-	if _, ok := command.Annotations["help:environment"]; ok {
-		helpEntries = append(helpEntries, helpEntry{"ENVIRONMENT VARIABLES", command.Annotations["help:environment"]})
-	}
-
-	if _, ok := command.Annotations["help:learn"]; ok {
-		helpEntries = append(helpEntries, helpEntry{"LEARN MORE", command.Annotations["help:learn"]})
-	}
-
-	if _, ok := command.Annotations["help:feedback"]; ok {
-		helpEntries = append(helpEntries, helpEntry{"FEEDBACK", command.Annotations["help:feedback"]})
+		if body != "" {
+			helpEntries = append(helpEntries, helpEntry{section.Title, body})
+		}
 	}
 
 	out := command.OutOrStdout()
@@ -155,7 +246,7 @@ func rootHelpFunc(command *cobra.Command, args []string) {
 			fmt.Fprintln(out, indent(strings.Trim(e.Body, "\r\n"), "  "))
 		} else {
 			// If there is no title print the body as is
-			fmt.Println(e.Body)
+			fmt.Fprintln(out, e.Body)
 		}
 		fmt.Fprintln(out)
 	}
@@ -0,0 +1,113 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCmd(use, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Run:   func(cmd *cobra.Command, args []string) {},
+	}
+}
+
+func rootHelp(t *testing.T, root *cobra.Command) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"--help"})
+	assert.NoError(t, root.Execute())
+	return out.String()
+}
+
+func TestCommandGroups(t *testing.T) {
+	t.Run("should list commands in their group sections", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		cmdx.SetHelp(root)
+
+		cmdx.AddCoreCommand(root, newCmd("namespace", "Manage namespaces"))
+		cmdx.AddOtherCommand(root, "auth", newCmd("login", "Login to the server"))
+		cmdx.AddAdditionalCommand(root, newCmd("version", "Print version information"))
+
+		help := rootHelp(t, root)
+		assert.Contains(t, help, "CORE COMMANDS\n  namespace   Manage namespaces\n")
+		assert.Contains(t, help, "AUTH COMMANDS\n  login       Login to the server\n")
+		assert.Regexp(t, `ADDITIONAL COMMANDS\n(  .*\n)*  version     Print version information\n`, help)
+	})
+
+	t.Run("should treat additional commands as core without groups", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		cmdx.SetHelp(root)
+
+		cmdx.AddAdditionalCommand(root, newCmd("version", "Print version information"))
+
+		help := rootHelp(t, root)
+		assert.Regexp(t, `CORE COMMANDS\n(  .*\n)*  version     Print version information\n`, help)
+		assert.NotContains(t, help, "ADDITIONAL COMMANDS")
+	})
+}
+
+func TestHelpConfig(t *testing.T) {
+	t.Run("should print sections in configured order with titles", func(t *testing.T) {
+		root := &cobra.Command{
+			Use:   "stencil",
+			Short: "Schema registry",
+			Annotations: map[string]string{
+				"help:getting-started": "$ stencil namespace list",
+				"help:learn":           "Use 'stencil <command> --help' for more information",
+			},
+		}
+		cmdx.SetHelp(root, cmdx.HelpConfig{
+			Sections: []cmdx.HelpSection{
+				{Key: "help:getting-started", Title: "GETTING STARTED"},
+				{Key: cmdx.HelpCoreCommands, Title: "COMMANDS"},
+				{Key: cmdx.HelpUsage, Title: "USAGE"},
+			},
+		})
+
+		cmdx.AddCoreCommand(root, newCmd("namespace", "Manage namespaces"))
+
+		help := rootHelp(t, root)
+		assert.Regexp(t, `^GETTING STARTED\n  \$ stencil namespace list\n\nCOMMANDS\n(  .*\n)*\nUSAGE\n  stencil \[flags\]\n\n$`, help)
+		assert.NotContains(t, help, "LEARN MORE")
+		assert.NotContains(t, help, "Schema registry")
+	})
+
+	t.Run("should format other command group titles", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		cfg := cmdx.DefaultHelpConfig()
+		for i, s := range cfg.Sections {
+			if s.Key == cmdx.HelpOtherCommands {
+				cfg.Sections[i].Title = "%s"
+			}
+		}
+		cmdx.SetHelp(root, cfg)
+
+		cmdx.AddOtherCommand(root, "auth", newCmd("login", "Login to the server"))
+		cmdx.AddOtherCommand(root, "admin", newCmd("reset", "Reset the server"))
+
+		help := rootHelp(t, root)
+		assert.Contains(t, help, "ADMIN\n  reset       Reset the server\n\nAUTH\n  login       Login to the server\n")
+	})
+
+	t.Run("should append group name to other command titles without format", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		cmdx.SetHelp(root, cmdx.HelpConfig{
+			Sections: []cmdx.HelpSection{
+				{Key: cmdx.HelpOtherCommands, Title: "100% COMMANDS"},
+			},
+		})
+
+		cmdx.AddOtherCommand(root, "auth", newCmd("login", "Login to the server"))
+
+		help := rootHelp(t, root)
+		assert.Equal(t, "100% COMMANDS AUTH\n  login       Login to the server\n\n", help)
+	})
+}
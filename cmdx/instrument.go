@@ -0,0 +1,36 @@
+package cmdx
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Instrument wraps the run function of the command and its subcommands
+// to report the command path, how long it ran and the returned error,
+// e.g. to record usage metrics. Errors before running the command,
+// like invalid flags, are not reported. It should be called once all
+// the subcommands are added.
+func Instrument(cmd *cobra.Command, reporter func(name string, dur time.Duration, err error)) {
+	for _, c := range cmd.Commands() {
+		Instrument(c, reporter)
+	}
+
+	switch {
+	case cmd.RunE != nil:
+		runE := cmd.RunE
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			start := time.Now()
+			err := runE(cmd, args)
+			reporter(cmd.CommandPath(), time.Since(start), err)
+			return err
+		}
+	case cmd.Run != nil:
+		run := cmd.Run
+		cmd.Run = func(cmd *cobra.Command, args []string) {
+			start := time.Now()
+			run(cmd, args)
+			reporter(cmd.CommandPath(), time.Since(start), nil)
+		}
+	}
+}
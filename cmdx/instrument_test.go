@@ -0,0 +1,82 @@
+package cmdx_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type report struct {
+	name string
+	dur  time.Duration
+	err  error
+}
+
+func TestInstrument(t *testing.T) {
+	errFailed := errors.New("failed")
+	newRoot := func(reports *[]report) *cobra.Command {
+		root := &cobra.Command{Use: "stencil", SilenceErrors: true, SilenceUsage: true}
+		namespace := &cobra.Command{Use: "namespace"}
+		namespace.AddCommand(
+			&cobra.Command{
+				Use: "create",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					time.Sleep(time.Millisecond)
+					return nil
+				},
+			},
+			&cobra.Command{
+				Use:  "delete",
+				RunE: func(cmd *cobra.Command, args []string) error { return errFailed },
+			},
+			&cobra.Command{
+				Use: "list",
+				Run: func(cmd *cobra.Command, args []string) {},
+			},
+		)
+		root.AddCommand(namespace)
+
+		cmdx.Instrument(root, func(name string, dur time.Duration, err error) {
+			*reports = append(*reports, report{name, dur, err})
+		})
+		return root
+	}
+
+	t.Run("should report successful commands", func(t *testing.T) {
+		var reports []report
+		root := newRoot(&reports)
+		root.SetArgs([]string{"namespace", "create"})
+
+		assert.NoError(t, root.Execute())
+		assert.Len(t, reports, 1)
+		assert.Equal(t, "stencil namespace create", reports[0].name)
+		assert.GreaterOrEqual(t, int64(reports[0].dur), int64(time.Millisecond))
+		assert.NoError(t, reports[0].err)
+	})
+
+	t.Run("should report returned errors", func(t *testing.T) {
+		var reports []report
+		root := newRoot(&reports)
+		root.SetArgs([]string{"namespace", "delete"})
+
+		assert.ErrorIs(t, root.Execute(), errFailed)
+		assert.Len(t, reports, 1)
+		assert.Equal(t, "stencil namespace delete", reports[0].name)
+		assert.ErrorIs(t, reports[0].err, errFailed)
+	})
+
+	t.Run("should report commands without errors", func(t *testing.T) {
+		var reports []report
+		root := newRoot(&reports)
+		root.SetArgs([]string{"namespace", "list"})
+
+		assert.NoError(t, root.Execute())
+		assert.Len(t, reports, 1)
+		assert.Equal(t, "stencil namespace list", reports[0].name)
+		assert.NoError(t, reports[0].err)
+	})
+}
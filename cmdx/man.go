@@ -4,24 +4,40 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/odpf/salt/printer"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // SetRefCmd is used to generate the reference documentation
 // in markdown format for the command tree.
 // This should be added on the root command and can
 // be used as `help reference` or `reference help`.
+// The reference can be written to a file with `--file`
+// in markdown, man or plain text using `--format`. The file
+// flag is not named `--output` so that it does not shadow
+// the output format flag added by RegisterOutputFlag.
 func SetRefCmd(root *cobra.Command) *cobra.Command {
+	var file, format string
+
 	cmd := &cobra.Command{
 		Use:   "reference",
 		Short: "Show command reference",
 		Long:  referenceLong(root),
-		Run:   referenceHelpFn(),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return writeReference(cmd, root, format, file)
+		},
 	}
 	cmd.SetHelpFunc(referenceHelpFn())
+	cmd.Flags().StringVar(&file, "file", "", "Write reference to file instead of stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", "markdown", "Reference format, one of: markdown|man|plain")
 	return cmd
 }
 
@@ -37,8 +53,49 @@ func referenceHelpFn() func(*cobra.Command, []string) {
 	}
 }
 
+func writeReference(cmd *cobra.Command, root *cobra.Command, format, file string) error {
+	var buf bytes.Buffer
+	switch format {
+	case "markdown":
+		if file == "" {
+			md, err := printer.Markdown(cmd.Long)
+			if err != nil {
+				return err
+			}
+			buf.WriteString(md)
+		} else {
+			buf.WriteString(cmd.Long)
+		}
+	case "man":
+		manReference(&buf, root)
+	case "plain":
+		plainReference(&buf, root)
+	default:
+		return fmt.Errorf("unknown reference format: %s", format)
+	}
+
+	if file == "" {
+		_, err := buf.WriteTo(cmd.OutOrStdout())
+		return err
+	}
+	return ioutil.WriteFile(file, buf.Bytes(), 0644)
+}
+
 func referenceLong(cmd *cobra.Command) string {
 	buf := bytes.NewBufferString(fmt.Sprintf("# %s reference\n\n", cmd.Name()))
+
+	// Table of contents
+	var toc bytes.Buffer
+	for _, c := range cmd.Commands() {
+		if c.Hidden {
+			continue
+		}
+		cmdTOC(&toc, c, 0)
+	}
+	if toc.Len() > 0 {
+		fmt.Fprintf(buf, "## Contents\n\n%s\n", toc.String())
+	}
+
 	for _, c := range cmd.Commands() {
 		if c.Hidden {
 			continue
@@ -48,20 +105,220 @@ func referenceLong(cmd *cobra.Command) string {
 	return buf.String()
 }
 
+// cmdTOC writes the table of contents entries linking to the
+// anchors of the command and its subcommands, nested by depth
+func cmdTOC(w io.Writer, cmd *cobra.Command, depth int) {
+	fmt.Fprintf(w, "%s- [%s](#%s)\n", strings.Repeat("  ", depth), cmd.CommandPath(), docSlug(cmd))
+
+	for _, c := range cmd.Commands() {
+		if c.Hidden {
+			continue
+		}
+		cmdTOC(w, c, depth+1)
+	}
+}
+
 func cmdRef(w io.Writer, cmd *cobra.Command, depth int) {
+	// Anchor named after the command path, stable unlike
+	// the ids generated from the usage in the heading
+	fmt.Fprintf(w, "<a id=\"%s\"></a>\n\n", docSlug(cmd))
+	cmdDoc(w, cmd, depth)
+
+	// Subcommands
+	for _, c := range cmd.Commands() {
+		if c.Hidden {
+			continue
+		}
+		cmdRef(w, c, depth+1)
+	}
+}
+
+// cmdDoc writes the markdown reference of the command
+// without its subcommands
+func cmdDoc(w io.Writer, cmd *cobra.Command, depth int) {
 	// Name + Description
 	fmt.Fprintf(w, "%s `%s`\n\n", strings.Repeat("#", depth), cmd.UseLine())
 	fmt.Fprintf(w, "%s\n\n", cmd.Short)
 
-	if flagUsages := cmd.Flags().FlagUsages(); flagUsages != "" {
+	if flagUsages := cmd.LocalFlags().FlagUsages(); flagUsages != "" {
 		fmt.Fprintf(w, "```\n%s````\n\n", dedent(flagUsages))
 	}
 
-	// Subcommands
+	if flagUsages := cmd.InheritedFlags().FlagUsages(); flagUsages != "" {
+		fmt.Fprintf(w, "Inherited flags:\n\n```\n%s````\n\n", dedent(flagUsages))
+	}
+}
+
+func plainReference(w io.Writer, root *cobra.Command) {
+	fmt.Fprintf(w, "%s reference\n\n", root.Name())
+	for _, c := range root.Commands() {
+		if c.Hidden {
+			continue
+		}
+		cmdPlainRef(w, c)
+	}
+}
+
+func cmdPlainRef(w io.Writer, cmd *cobra.Command) {
+	fmt.Fprintf(w, "%s\n", cmd.UseLine())
+	fmt.Fprintf(w, "%s\n\n", indent(cmd.Short, "  "))
+
+	if flagUsages := cmd.LocalFlags().FlagUsages(); flagUsages != "" {
+		fmt.Fprintf(w, "%s\n\n", indent(dedent(strings.TrimRight(flagUsages, "\n")), "    "))
+	}
+
+	if flagUsages := cmd.InheritedFlags().FlagUsages(); flagUsages != "" {
+		fmt.Fprintf(w, "  Inherited flags:\n%s\n\n", indent(dedent(strings.TrimRight(flagUsages, "\n")), "    "))
+	}
+
 	for _, c := range cmd.Commands() {
 		if c.Hidden {
 			continue
 		}
-		cmdRef(w, c, depth+1)
+		cmdPlainRef(w, c)
 	}
 }
+
+func manReference(w io.Writer, root *cobra.Command) {
+	fmt.Fprintf(w, ".TH %q \"1\" \"\" %q %q\n", strings.ToUpper(root.Name()), root.Name(), root.Name()+" manual")
+	fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", manEscape(root.Name()), manEscape(root.Short))
+	fmt.Fprint(w, ".SH COMMANDS\n")
+	for _, c := range root.Commands() {
+		if c.Hidden {
+			continue
+		}
+		cmdManRef(w, c)
+	}
+}
+
+func cmdManRef(w io.Writer, cmd *cobra.Command) {
+	fmt.Fprintf(w, ".SS %s\n%s\n", manEscape(cmd.UseLine()), manEscape(cmd.Short))
+	manFlags(w, cmd.LocalFlags())
+	if cmd.HasAvailableInheritedFlags() {
+		fmt.Fprint(w, ".PP\nInherited flags:\n")
+		manFlags(w, cmd.InheritedFlags())
+	}
+
+	for _, c := range cmd.Commands() {
+		if c.Hidden {
+			continue
+		}
+		cmdManRef(w, c)
+	}
+}
+
+// ManOption configures the generated man pages.
+type ManOption func(*manOptions)
+
+type manOptions struct {
+	section string
+}
+
+// WithManSection sets the manual section of the generated
+// man pages, defaults to "1".
+func WithManSection(section string) ManOption {
+	return func(o *manOptions) {
+		o.section = section
+	}
+}
+
+// GenManTree generates a roff man page for the command
+// and each of its subcommands in the given directory.
+// Files are named after the command path, e.g. `app-sub.1`.
+func GenManTree(cmd *cobra.Command, dir string, opts ...ManOption) error {
+	o := &manOptions{section: "1"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return genManTree(cmd, dir, o)
+}
+
+func genManTree(cmd *cobra.Command, dir string, o *manOptions) error {
+	for _, c := range cmd.Commands() {
+		if c.Hidden {
+			continue
+		}
+		if err := genManTree(c, dir, o); err != nil {
+			return err
+		}
+	}
+
+	name := strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+	f, err := os.Create(filepath.Join(dir, name+"."+o.section))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	manRef(f, cmd, o.section)
+	return nil
+}
+
+func manRef(w io.Writer, cmd *cobra.Command, section string) {
+	name := strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+	root := cmd.Root().Name()
+
+	fmt.Fprintf(w, ".TH %q %q \"\" %q %q\n", strings.ToUpper(name), section, root, root+" manual")
+
+	fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", manEscape(name), manEscape(cmd.Short))
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n", manEscape(cmd.UseLine()))
+
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", manEscape(description))
+
+	if cmd.HasAvailableLocalFlags() {
+		fmt.Fprint(w, ".SH OPTIONS\n")
+		manFlags(w, cmd.LocalFlags())
+	}
+
+	if cmd.HasAvailableInheritedFlags() {
+		fmt.Fprint(w, ".SH INHERITED OPTIONS\n")
+		manFlags(w, cmd.InheritedFlags())
+	}
+
+	if cmd.Example != "" {
+		fmt.Fprintf(w, ".SH EXAMPLES\n.nf\n%s\n.fi\n", manEscape(cmd.Example))
+	}
+
+	if cmd.HasParent() {
+		parent := strings.ReplaceAll(cmd.Parent().CommandPath(), " ", "-")
+		fmt.Fprintf(w, ".SH SEE ALSO\n.BR %s (%s)\n", manEscape(parent), section)
+	}
+}
+
+// manFlags writes the visible flags as a roff tagged paragraph list.
+func manFlags(w io.Writer, flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(manFlag(f)), manEscape(f.Usage))
+	})
+}
+
+func manFlag(f *pflag.Flag) string {
+	flag := "--" + f.Name
+	if f.Shorthand != "" {
+		flag = "-" + f.Shorthand + ", " + flag
+	}
+	if f.Value.Type() != "bool" {
+		flag += " " + f.Value.Type()
+	}
+	return flag
+}
+
+var manLineStartRE = regexp.MustCompile(`(?m)^([.'])`)
+
+// manEscape escapes text so that it is rendered as is by roff.
+func manEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	return manLineStartRE.ReplaceAllString(strings.TrimSpace(s), `\&$1`)
+}
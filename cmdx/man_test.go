@@ -0,0 +1,162 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenManTree(t *testing.T) {
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		root.PersistentFlags().String("host", "", "Server host")
+		sub := &cobra.Command{
+			Use:     "create <name>",
+			Short:   "Create a namespace",
+			Long:    "Create a namespace in the schema registry.",
+			Example: "$ stencil create my-namespace",
+			Run:     func(cmd *cobra.Command, args []string) {},
+		}
+		sub.Flags().StringP("format", "f", "", "Schema format")
+		sub.Flags().Bool("dry-run", false, "Print without creating")
+		hidden := &cobra.Command{Use: "hidden", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}}
+		root.AddCommand(sub, hidden)
+		return root
+	}
+
+	t.Run("should write a man page per command", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "cmdx")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		err = cmdx.GenManTree(newRoot(), dir)
+		assert.NoError(t, err)
+
+		files, err := ioutil.ReadDir(dir)
+		assert.NoError(t, err)
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		assert.Equal(t, []string{"stencil-create.1", "stencil.1"}, names)
+
+		page, err := ioutil.ReadFile(filepath.Join(dir, "stencil-create.1"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(page), `.TH "STENCIL-CREATE" "1" "" "stencil" "stencil manual"`)
+		assert.Contains(t, string(page), ".SH NAME\nstencil\\-create \\- Create a namespace\n")
+		assert.Contains(t, string(page), ".SH SYNOPSIS\n.B stencil create <name> [flags]\n")
+		assert.Contains(t, string(page), ".SH DESCRIPTION\nCreate a namespace in the schema registry.\n")
+		assert.Contains(t, string(page), ".SH OPTIONS\n")
+		assert.Contains(t, string(page), ".TP\n.B \\-f, \\-\\-format string\nSchema format\n")
+		assert.Contains(t, string(page), ".TP\n.B \\-\\-dry\\-run\nPrint without creating\n")
+		assert.Contains(t, string(page), ".SH INHERITED OPTIONS\n.TP\n.B \\-\\-host string\nServer host\n")
+		assert.Contains(t, string(page), ".SH EXAMPLES\n.nf\n$ stencil create my\\-namespace\n.fi\n")
+		assert.Contains(t, string(page), ".SH SEE ALSO\n.BR stencil (1)\n")
+	})
+
+	t.Run("should use configured section", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "cmdx")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		err = cmdx.GenManTree(newRoot(), dir, cmdx.WithManSection("8"))
+		assert.NoError(t, err)
+
+		page, err := ioutil.ReadFile(filepath.Join(dir, "stencil.8"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(page), `.TH "STENCIL" "8" "" "stencil" "stencil manual"`)
+	})
+}
+
+func TestRefCmd(t *testing.T) {
+	execute := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		root.PersistentFlags().String("host", "", "Server host")
+		sub := &cobra.Command{Use: "create <name>", Short: "Create a namespace", Run: func(cmd *cobra.Command, args []string) {}}
+		sub.Flags().StringP("format", "f", "", "Schema format")
+		root.AddCommand(sub)
+		root.AddCommand(cmdx.SetRefCmd(root))
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"reference"}, args...))
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	tests := []struct {
+		format   string
+		contains []string
+	}{
+		{
+			format:   "markdown",
+			contains: []string{"# stencil reference\n", "## `stencil create <name> [flags]`\n\nCreate a namespace\n", "-f, --format string   Schema format", "Inherited flags:\n\n```\n--host string   Server host\n````\n"},
+		},
+		{
+			format:   "man",
+			contains: []string{`.TH "STENCIL" "1"`, ".SH COMMANDS\n", ".SS stencil create <name> [flags]\nCreate a namespace\n", ".TP\n.B \\-f, \\-\\-format string\nSchema format\n", ".PP\nInherited flags:\n.TP\n.B \\-\\-host string\nServer host\n"},
+		},
+		{
+			format:   "plain",
+			contains: []string{"stencil reference\n\n", "stencil create <name> [flags]\n  Create a namespace\n\n", "    -f, --format string   Schema format\n", "  Inherited flags:\n    --host string   Server host\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("should write "+tt.format+" reference to file", func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cmdx")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			file := filepath.Join(dir, "reference")
+
+			out, err := execute(t, "--format", tt.format, "--file", file)
+			assert.NoError(t, err)
+			assert.Empty(t, out)
+
+			ref, err := ioutil.ReadFile(file)
+			assert.NoError(t, err)
+			for _, c := range tt.contains {
+				assert.Contains(t, string(ref), c)
+			}
+			assert.NotContains(t, string(ref), "\x1b[")
+		})
+	}
+
+	t.Run("should link table of contents to command anchors", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		namespace := &cobra.Command{Use: "namespace", Short: "Manage namespaces"}
+		namespace.AddCommand(&cobra.Command{Use: "create <name>", Short: "Create a namespace", Run: func(cmd *cobra.Command, args []string) {}})
+		root.AddCommand(namespace)
+		root.AddCommand(&cobra.Command{Use: "hidden", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}})
+		ref := cmdx.SetRefCmd(root)
+
+		assert.Contains(t, ref.Long, "# stencil reference\n\n## Contents\n\n"+
+			"- [stencil namespace](#stencil-namespace)\n"+
+			"  - [stencil namespace create](#stencil-namespace-create)\n\n")
+		assert.Contains(t, ref.Long, "<a id=\"stencil-namespace\"></a>\n\n## `stencil namespace`\n")
+		assert.Contains(t, ref.Long, "<a id=\"stencil-namespace-create\"></a>\n\n### `stencil namespace create <name>`\n")
+		assert.NotContains(t, ref.Long, "hidden")
+	})
+
+	t.Run("should write plain reference to stdout", func(t *testing.T) {
+		out, err := execute(t, "--format", "plain")
+
+		assert.NoError(t, err)
+		assert.Contains(t, out, "stencil create <name> [flags]\n  Create a namespace\n")
+	})
+
+	t.Run("should return error for unknown format", func(t *testing.T) {
+		_, err := execute(t, "--format", "html")
+
+		assert.EqualError(t, err, "unknown reference format: html")
+	})
+}
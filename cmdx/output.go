@@ -0,0 +1,81 @@
+package cmdx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/odpf/salt/printer"
+	"github.com/spf13/cobra"
+)
+
+// OutputFormat is the format commands print their results in.
+type OutputFormat string
+
+const (
+	OutputTable OutputFormat = "table"
+	OutputJSON  OutputFormat = "json"
+	OutputYAML  OutputFormat = "yaml"
+)
+
+var outputFormats = []OutputFormat{OutputTable, OutputJSON, OutputYAML}
+
+// Tabular is implemented by values printed as a table,
+// the rows are printed as is so the first row may be a header.
+type Tabular interface {
+	TableRows() [][]string
+}
+
+// RegisterOutputFlag adds the persistent `--output` flag to the
+// command to choose the format of the output, table by default.
+func RegisterOutputFlag(cmd *cobra.Command) {
+	names := make([]string, len(outputFormats))
+	for i, f := range outputFormats {
+		names[i] = string(f)
+	}
+	cmd.PersistentFlags().StringP("output", "o", string(OutputTable),
+		fmt.Sprintf("Output format, one of: %s", strings.Join(names, ", ")))
+}
+
+// GetOutputFormat returns the format set with the `--output` flag.
+// It returns a UserError if the format is not supported.
+func GetOutputFormat(cmd *cobra.Command) (OutputFormat, error) {
+	value, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range outputFormats {
+		if OutputFormat(strings.ToLower(value)) == f {
+			return f, nil
+		}
+	}
+	return "", NewUserError("invalid output format %q, must be one of table, json or yaml", value)
+}
+
+// Print writes v to the command output in the format set with
+// the `--output` flag. To be printed as a table v must be a
+// [][]string or implement Tabular.
+func Print(cmd *cobra.Command, v interface{}) error {
+	format, err := GetOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	switch format {
+	case OutputJSON:
+		return printer.JSON(out, v)
+	case OutputYAML:
+		return printer.YAML(out, v)
+	}
+
+	switch rows := v.(type) {
+	case [][]string:
+		printer.Table(out, rows)
+	case Tabular:
+		printer.Table(out, rows.TableRows())
+	default:
+		return fmt.Errorf("unable to print %T as a table", v)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type namespace struct {
+	Name   string `json:"name" yaml:"name"`
+	Format string `json:"format" yaml:"format"`
+}
+
+type namespaces []namespace
+
+func (n namespaces) TableRows() [][]string {
+	rows := [][]string{{"NAME", "FORMAT"}}
+	for _, ns := range n {
+		rows = append(rows, []string{ns.Name, ns.Format})
+	}
+	return rows
+}
+
+func TestPrint(t *testing.T) {
+	execute := func(t *testing.T, v interface{}, args ...string) (string, error) {
+		t.Helper()
+
+		root := &cobra.Command{Use: "stencil", SilenceErrors: true, SilenceUsage: true}
+		cmdx.RegisterOutputFlag(root)
+		list := &cobra.Command{
+			Use: "list",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return cmdx.Print(cmd, v)
+			},
+		}
+		root.AddCommand(list)
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(append([]string{"list"}, args...))
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	list := namespaces{{Name: "quickstart", Format: "protobuf"}}
+
+	t.Run("should print table by default", func(t *testing.T) {
+		out, err := execute(t, list)
+		assert.NoError(t, err)
+		assert.Equal(t, "NAME      \tFORMAT  \t\nquickstart\tprotobuf\t\n", out)
+	})
+
+	t.Run("should print rows as table", func(t *testing.T) {
+		out, err := execute(t, [][]string{{"quickstart", "protobuf"}}, "-o", "table")
+		assert.NoError(t, err)
+		assert.Equal(t, "quickstart\tprotobuf\t\n", out)
+	})
+
+	t.Run("should print json", func(t *testing.T) {
+		out, err := execute(t, list, "--output", "json")
+		assert.NoError(t, err)
+		assert.Equal(t, "[\n  {\n    \"name\": \"quickstart\",\n    \"format\": \"protobuf\"\n  }\n]\n", out)
+	})
+
+	t.Run("should print yaml", func(t *testing.T) {
+		out, err := execute(t, list, "--output", "YAML")
+		assert.NoError(t, err)
+		assert.Equal(t, "- name: quickstart\n  format: protobuf\n", out)
+	})
+
+	t.Run("should return user error for invalid format", func(t *testing.T) {
+		out, err := execute(t, list, "--output", "xml")
+		assert.EqualError(t, err, "invalid output format \"xml\", must be one of table, json or yaml")
+		var userErr *cmdx.UserError
+		assert.True(t, errors.As(err, &userErr))
+		assert.Empty(t, out)
+	})
+
+	t.Run("should return error if value can not be printed as table", func(t *testing.T) {
+		_, err := execute(t, namespace{Name: "quickstart"})
+		assert.EqualError(t, err, "unable to print cmdx_test.namespace as a table")
+	})
+}
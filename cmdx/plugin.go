@@ -0,0 +1,181 @@
+package cmdx
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// EnablePlugins runs the executable named `<prefix>-<name>` found in
+// PATH for an unknown command, so `<root> <name> [args]` runs the
+// executable with the args and the environment, like git and gh
+// extensions. The executable is looked up only when the command is
+// unknown, the flags after the name are passed to it, so the root
+// flags must be set before the name. Built-in commands take precedence,
+// unknown commands which are not plugins are reported as usual. Plugins
+// are listed under PLUGIN COMMANDS in the help set by SetHelp.
+//
+// The root command runs the plugin, so its Run, if any, only runs when
+// no plugin is given. Execute exits with the exit code of the plugin.
+func EnablePlugins(root *cobra.Command, prefix string) {
+	setAnnotation(root, "plugins:prefix", prefix)
+	// stop parsing the root flags at the plugin name
+	root.Flags().SetInterspersed(false)
+
+	validate := root.Args
+	root.Args = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			if _, err := exec.LookPath(prefix + "-" + args[0]); err == nil {
+				return nil
+			}
+		}
+		if validate != nil {
+			return validate(cmd, args)
+		}
+		if len(args) > 0 {
+			return fmt.Errorf("unknown command %q for %q%s", args[0], cmd.CommandPath(), suggestions(cmd, args[0]))
+		}
+		return nil
+	}
+
+	run, runE := root.Run, root.RunE
+	root.Run = nil
+	root.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			if path, err := exec.LookPath(prefix + "-" + args[0]); err == nil {
+				return runPlugin(cmd, args[0], path, args[1:])
+			}
+		}
+		switch {
+		case runE != nil:
+			return runE(cmd, args)
+		case run != nil:
+			run(cmd, args)
+			return nil
+		default:
+			return cmd.Help()
+		}
+	}
+}
+
+// PluginExitError is returned when a plugin exits with a non-zero
+// code. Execute exits with the same code without printing it, as
+// the plugin reports its own errors.
+type PluginExitError struct {
+	Name string
+	Err  *exec.ExitError
+}
+
+func (e *PluginExitError) Error() string {
+	return fmt.Sprintf("plugin %s: %s", e.Name, e.Err)
+}
+
+func (e *PluginExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of the plugin, or
+// ExitError if it was terminated by a signal.
+func (e *PluginExitError) ExitCode() int {
+	if code := e.Err.ExitCode(); code > 0 {
+		return code
+	}
+	return ExitError
+}
+
+func runPlugin(cmd *cobra.Command, name, path string, args []string) error {
+	c := exec.Command(path, args...)
+	c.Stdin = cmd.InOrStdin()
+	c.Stdout = cmd.OutOrStdout()
+	c.Stderr = cmd.ErrOrStderr()
+	c.Env = os.Environ()
+
+	err := c.Run()
+	if exitErr := new(exec.ExitError); errors.As(err, &exitErr) {
+		return &PluginExitError{Name: name, Err: exitErr}
+	} else if err != nil {
+		return fmt.Errorf("running plugin %s: %w", name, err)
+	}
+	return nil
+}
+
+// suggestions returns the suggestions of cobra for an unknown command
+func suggestions(cmd *cobra.Command, arg string) string {
+	if cmd.DisableSuggestions {
+		return ""
+	}
+	if cmd.SuggestionsMinimumDistance <= 0 {
+		cmd.SuggestionsMinimumDistance = 2
+	}
+
+	var s string
+	if names := cmd.SuggestionsFor(arg); len(names) > 0 {
+		s = "\n\nDid you mean this?\n"
+		for _, name := range names {
+			s += fmt.Sprintf("\t%v\n", name)
+		}
+	}
+	return s
+}
+
+// addPluginCommands adds a command for each plugin found in PATH to
+// list them in help, PATH is only scanned when the help is printed
+func addPluginCommands(root *cobra.Command, prefix string) {
+	for name, path := range findPlugins(prefix) {
+		if cmd, _, err := root.Find([]string{name}); err == nil && cmd != root {
+			continue
+		}
+		AddOtherCommand(root, "plugin", pluginCmd(name, path))
+	}
+}
+
+func pluginCmd(name, path string) *cobra.Command {
+	return &cobra.Command{
+		Use:                name,
+		Short:              fmt.Sprintf("Run the %s plugin", filepath.Base(path)),
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(cmd, name, path, args)
+		},
+	}
+}
+
+// findPlugins returns the paths of the plugins in PATH by name,
+// the first one found wins like for any other executable.
+func findPlugins(prefix string) map[string]string {
+	plugins := map[string]string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, f := range files {
+			if f.IsDir() || !strings.HasPrefix(f.Name(), prefix+"-") || !isExecutable(f) {
+				continue
+			}
+			name := strings.TrimPrefix(f.Name(), prefix+"-")
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if _, ok := plugins[name]; name != "" && !ok {
+				plugins[name] = filepath.Join(dir, f.Name())
+			}
+		}
+	}
+	return plugins
+}
+
+func isExecutable(f os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(f.Name()), ".exe")
+	}
+	return f.Mode()&0111 != 0
+}
@@ -0,0 +1,99 @@
+// +build !windows
+
+package cmdx_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnablePlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmdx")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	script := "#!/bin/sh\necho \"args: $*\"\necho \"env: $STENCIL_HOST\"\n"
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stencil-hello"), []byte(script), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stencil-version"), []byte(script), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stencil-notes"), []byte(script), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stencil-fail"), []byte("#!/bin/sh\nexit 3\n"), 0755))
+	defer setenv(t, "PATH", dir)()
+	defer setenv(t, "STENCIL_HOST", "localhost:8080")()
+
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "stencil", SilenceErrors: true, SilenceUsage: true}
+		root.PersistentFlags().String("host", "", "")
+		root.AddCommand(&cobra.Command{
+			Use: "version",
+			Run: func(cmd *cobra.Command, args []string) { cmd.Println("built-in") },
+		})
+		cmdx.EnablePlugins(root, "stencil")
+		return root
+	}
+
+	execute := func(args ...string) (string, error) {
+		root := newRoot()
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	t.Run("should run plugin with args and env", func(t *testing.T) {
+		out, err := execute("hello", "world", "--name", "foo", "-v")
+		assert.NoError(t, err)
+		assert.Equal(t, "args: world --name foo -v\nenv: localhost:8080\n", out)
+	})
+
+	t.Run("should prefer built-in commands", func(t *testing.T) {
+		out, err := execute("version")
+		assert.NoError(t, err)
+		assert.Equal(t, "built-in\n", out)
+	})
+
+	t.Run("should parse root flags before plugin name", func(t *testing.T) {
+		out, err := execute("--host", "localhost", "hello", "--host", "remote")
+		assert.NoError(t, err)
+		assert.Equal(t, "args: --host remote\nenv: localhost:8080\n", out)
+	})
+
+	t.Run("should report unknown commands if plugin is not found", func(t *testing.T) {
+		_, err := execute("notes")
+		assert.EqualError(t, err, "unknown command \"notes\" for \"stencil\"")
+	})
+
+	t.Run("should suggest built-in commands if plugin is not found", func(t *testing.T) {
+		_, err := execute("versio")
+		assert.EqualError(t, err, "unknown command \"versio\" for \"stencil\"\n\nDid you mean this?\n\tversion\n")
+	})
+
+	t.Run("should exit with exit code of plugin", func(t *testing.T) {
+		root := newRoot()
+		var errOut bytes.Buffer
+		root.SetErr(&errOut)
+		root.SetArgs([]string{"fail"})
+
+		assert.Equal(t, 3, cmdx.Execute(root))
+		assert.Empty(t, errOut.String())
+	})
+
+	t.Run("should list plugins in help", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil", Short: "Schema registry"}
+		cmdx.SetHelp(root)
+		cmdx.AddCoreCommand(root, newCmd("namespace", "Manage namespaces"))
+		cmdx.EnablePlugins(root, "stencil")
+
+		help := rootHelp(t, root)
+		assert.Contains(t, help, "PLUGIN COMMANDS\n  fail")
+		assert.Contains(t, help, "  hello       Run the stencil-hello plugin\n")
+	})
+}
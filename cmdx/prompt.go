@@ -0,0 +1,175 @@
+package cmdx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ErrNotInteractive is returned when prompting without a terminal.
+var ErrNotInteractive = errors.New("cannot prompt for input: not a terminal")
+
+// Prompt asks questions on Out and reads the answers from In.
+type Prompt struct {
+	In  io.Reader
+	Out io.Writer
+
+	// AssumeYes answers yes to every confirmation without
+	// reading from In, e.g. when `--yes` flag is set.
+	AssumeYes bool
+
+	reader *bufio.Reader
+}
+
+// NewPrompt returns a prompt reading from stdin and writing to stderr.
+func NewPrompt() *Prompt {
+	return &Prompt{
+		In:  Stdin,
+		Out: os.Stderr,
+	}
+}
+
+// Input asks for a line of text and returns it without
+// the surrounding whitespace.
+func (p *Prompt) Input(prompt string) (string, error) {
+	if p.reader == nil {
+		p.reader = bufio.NewReader(p.In)
+	}
+
+	fmt.Fprintf(p.Out, "%s ", prompt)
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks a yes/no question that defaults to no.
+func (p *Prompt) Confirm(prompt string) (bool, error) {
+	return p.ConfirmWithDefault(prompt, false)
+}
+
+// ConfirmWithDefault asks a yes/no question and returns def
+// if the answer is empty. Invalid answers are asked again.
+func (p *Prompt) ConfirmWithDefault(prompt string, def bool) (bool, error) {
+	if p.AssumeYes {
+		return true, nil
+	}
+
+	options := "[y/N]"
+	if def {
+		options = "[Y/n]"
+	}
+
+	if p.reader == nil {
+		p.reader = bufio.NewReader(p.In)
+	}
+
+	for {
+		fmt.Fprintf(p.Out, "%s %s ", prompt, options)
+
+		line, err := p.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+
+		if err == io.EOF {
+			return false, io.ErrUnexpectedEOF
+		}
+		fmt.Fprintln(p.Out, "Please answer y or n.")
+	}
+}
+
+// Prompter asks for input, it is implemented by Prompt.
+type Prompter interface {
+	Input(prompt string) (string, error)
+}
+
+// PromptMissingFlags asks for the values of the required flags
+// of the command that are not set, before the command runs.
+// If p is nil the values are asked on the terminal, or not at
+// all if the command is not interactive, failing with cobra's
+// required flag error.
+func PromptMissingFlags(cmd *cobra.Command, p Prompter) {
+	preRunE, preRun := cmd.PreRunE, cmd.PreRun
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		prompter := p
+		if prompter == nil && IsInteractive() {
+			prompter = NewPrompt()
+		}
+		if prompter != nil {
+			if err := promptFlags(cmd, prompter); err != nil {
+				return err
+			}
+		}
+
+		if preRunE != nil {
+			return preRunE(cmd, args)
+		}
+		if preRun != nil {
+			preRun(cmd, args)
+		}
+		return nil
+	}
+}
+
+func promptFlags(cmd *cobra.Command, p Prompter) error {
+	var missing []*pflag.Flag
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		required := f.Annotations[cobra.BashCompOneRequiredFlag]
+		if len(required) > 0 && required[0] == "true" && !f.Changed {
+			missing = append(missing, f)
+		}
+	})
+
+	for _, f := range missing {
+		label := f.Usage
+		if label == "" {
+			label = f.Name
+		}
+
+		value, err := p.Input(fmt.Sprintf("%s (--%s):", label, f.Name))
+		if err != nil {
+			return err
+		}
+		// leave empty flags unset so cobra reports them as missing
+		if value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(f.Name, value); err != nil {
+			return &UserError{Err: fmt.Errorf("invalid value for --%s: %w", f.Name, err)}
+		}
+	}
+	return nil
+}
+
+// Confirm asks a yes/no question on the terminal that defaults to no.
+// It returns ErrNotInteractive if stdin or stdout is not a terminal.
+func Confirm(prompt string) (bool, error) {
+	return ConfirmWithDefault(prompt, false)
+}
+
+// ConfirmWithDefault asks a yes/no question on the terminal and returns def
+// if the answer is empty. It returns ErrNotInteractive if stdin or stdout
+// is not a terminal.
+func ConfirmWithDefault(prompt string, def bool) (bool, error) {
+	if !IsInteractive() {
+		return false, ErrNotInteractive
+	}
+	return NewPrompt().ConfirmWithDefault(prompt, def)
+}
@@ -0,0 +1,142 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		def      bool
+		expected bool
+	}{
+		{name: "should return true for y", input: "y\n", expected: true},
+		{name: "should return true for yes", input: "YES\n", expected: true},
+		{name: "should return false for n", input: "n\n", def: true, expected: false},
+		{name: "should return default false for empty answer", input: "\n", expected: false},
+		{name: "should return default true for empty answer", input: "\n", def: true, expected: true},
+		{name: "should accept answer without trailing newline", input: "y", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := &cmdx.Prompt{In: strings.NewReader(tt.input), Out: &out}
+
+			ok, err := p.ConfirmWithDefault("Delete namespace?", tt.def)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+
+	t.Run("should show default in prompt", func(t *testing.T) {
+		var out bytes.Buffer
+		p := &cmdx.Prompt{In: strings.NewReader("\n"), Out: &out}
+
+		_, err := p.Confirm("Delete namespace?")
+		assert.NoError(t, err)
+		assert.Equal(t, "Delete namespace? [y/N] ", out.String())
+	})
+
+	t.Run("should ask again for invalid answer", func(t *testing.T) {
+		var out bytes.Buffer
+		p := &cmdx.Prompt{In: strings.NewReader("maybe\ny\n"), Out: &out}
+
+		ok, err := p.Confirm("Delete namespace?")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "Delete namespace? [y/N] Please answer y or n.\nDelete namespace? [y/N] ", out.String())
+	})
+
+	t.Run("should return error if input ends without an answer", func(t *testing.T) {
+		p := &cmdx.Prompt{In: strings.NewReader(""), Out: &bytes.Buffer{}}
+
+		_, err := p.Confirm("Delete namespace?")
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("should not read input if yes is assumed", func(t *testing.T) {
+		var out bytes.Buffer
+		p := &cmdx.Prompt{In: strings.NewReader(""), Out: &out, AssumeYes: true}
+
+		ok, err := p.Confirm("Delete namespace?")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestPromptMissingFlags(t *testing.T) {
+	newDeleteCmd := func(name *string) *cobra.Command {
+		cmd := &cobra.Command{
+			Use: "delete",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return nil
+			},
+		}
+		cmd.Flags().StringVar(name, "name", "", "Name of the namespace")
+		cmd.Flags().String("project", "", "")
+		cmd.MarkFlagRequired("name")
+		cmd.MarkFlagRequired("project")
+		return cmd
+	}
+
+	t.Run("should prompt for missing required flags", func(t *testing.T) {
+		var name string
+		cmd := newDeleteCmd(&name)
+		var out bytes.Buffer
+		cmdx.PromptMissingFlags(cmd, &cmdx.Prompt{In: strings.NewReader("foo\nbar\n"), Out: &out})
+
+		cmd.SetArgs([]string{})
+		assert.NoError(t, cmd.Execute())
+		assert.Equal(t, "foo", name)
+		assert.Equal(t, "bar", cmd.Flag("project").Value.String())
+		assert.Equal(t, "Name of the namespace (--name): project (--project): ", out.String())
+	})
+
+	t.Run("should not prompt for flags already set", func(t *testing.T) {
+		var name string
+		cmd := newDeleteCmd(&name)
+		var out bytes.Buffer
+		cmdx.PromptMissingFlags(cmd, &cmdx.Prompt{In: strings.NewReader("bar\n"), Out: &out})
+
+		cmd.SetArgs([]string{"--name", "foo"})
+		assert.NoError(t, cmd.Execute())
+		assert.Equal(t, "foo", name)
+		assert.Equal(t, "project (--project): ", out.String())
+	})
+
+	t.Run("should run existing pre run", func(t *testing.T) {
+		var name string
+		cmd := newDeleteCmd(&name)
+		preRunCalled := false
+		cmd.PreRun = func(cmd *cobra.Command, args []string) {
+			preRunCalled = true
+		}
+		cmdx.PromptMissingFlags(cmd, &cmdx.Prompt{In: strings.NewReader("foo\nbar\n"), Out: &bytes.Buffer{}})
+
+		cmd.SetArgs([]string{})
+		assert.NoError(t, cmd.Execute())
+		assert.True(t, preRunCalled)
+	})
+
+	t.Run("should return required flag error if not interactive", func(t *testing.T) {
+		var name string
+		cmd := newDeleteCmd(&name)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmdx.PromptMissingFlags(cmd, nil)
+
+		cmd.SetArgs([]string{"--name", "foo"})
+		err := cmd.Execute()
+		assert.EqualError(t, err, `required flag(s) "project" not set`)
+	})
+}
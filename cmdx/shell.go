@@ -1,8 +1,6 @@
 package cmdx
 
 import (
-	"os"
-
 	"github.com/MakeNowJust/heredoc"
 	"github.com/spf13/cobra"
 )
@@ -16,16 +14,16 @@ func SetCompletionCmd(exec string) *cobra.Command {
 		Use:   "completion [bash|zsh|fish|powershell]",
 		Short: "Generate shell completion scripts",
 		Long: heredoc.Docf(`To load completions:
-	
+
 			Bash:
 
-			  $ source <(%s completion bash)
+			  $ source <(%[1]s completion bash)
 
 			  # To load completions for each session, execute once:
 			  # Linux:
-			  $ %s completion bash > /etc/bash_completion.d/%s
+			  $ %[1]s completion bash > /etc/bash_completion.d/%[1]s
 			  # macOS:
-			  $ %s completion bash > /usr/local/etc/bash_completion.d/%s
+			  $ %[1]s completion bash > /usr/local/etc/bash_completion.d/%[1]s
 
 			Zsh:
 
@@ -35,39 +33,41 @@ func SetCompletionCmd(exec string) *cobra.Command {
 			  $ echo "autoload -U compinit; compinit" >> ~/.zshrc
 
 			  # To load completions for each session, execute once:
-			  $ %s completion zsh > "${fpath[1]}/_yourprogram"
+			  $ %[1]s completion zsh > "${fpath[1]}/_%[1]s"
 
 			  # You will need to start a new shell for this setup to take effect.
 
 			fish:
 
-			  $ %s completion fish | source
+			  $ %[1]s completion fish | source
 
 			  # To load completions for each session, execute once:
-			  $ %s completion fish > ~/.config/fish/completions/%s.fish
+			  $ %[1]s completion fish > ~/.config/fish/completions/%[1]s.fish
 
 			PowerShell:
 
-			  PS> %s completion powershell | Out-String | Invoke-Expression
+			  PS> %[1]s completion powershell | Out-String | Invoke-Expression
 
 			  # To load completions for every new session, run:
-			  PS> %s completion powershell > %s.ps1
+			  PS> %[1]s completion powershell > %[1]s.ps1
 			  # and source this file from your PowerShell profile.
 		`, exec),
 		DisableFlagsInUseLine: true,
 		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 		Args:                  cobra.ExactValidArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
 			switch args[0] {
 			case "bash":
-				cmd.Root().GenBashCompletion(os.Stdout)
+				return cmd.Root().GenBashCompletionV2(out, true)
 			case "zsh":
-				cmd.Root().GenZshCompletion(os.Stdout)
+				return cmd.Root().GenZshCompletion(out)
 			case "fish":
-				cmd.Root().GenFishCompletion(os.Stdout, true)
+				return cmd.Root().GenFishCompletion(out, true)
 			case "powershell":
-				cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+				return cmd.Root().GenPowerShellCompletionWithDesc(out)
 			}
+			return nil
 		},
 	}
 }
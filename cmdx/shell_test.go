@@ -0,0 +1,55 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionCmd(t *testing.T) {
+	tests := []struct {
+		shell    string
+		contains string
+	}{
+		{shell: "bash", contains: "# bash completion V2 for stencil"},
+		{shell: "zsh", contains: "#compdef _stencil stencil"},
+		{shell: "fish", contains: "complete -c stencil"},
+		{shell: "powershell", contains: "Register-ArgumentCompleter"},
+	}
+
+	for _, tt := range tests {
+		t.Run("should generate "+tt.shell+" completion", func(t *testing.T) {
+			root := &cobra.Command{Use: "stencil"}
+			root.AddCommand(cmdx.SetCompletionCmd("stencil"))
+
+			var out bytes.Buffer
+			root.SetOut(&out)
+			root.SetArgs([]string{"completion", tt.shell})
+
+			err := root.Execute()
+			assert.NoError(t, err)
+			assert.Contains(t, out.String(), tt.contains)
+		})
+	}
+
+	t.Run("should explain installation using the executable name", func(t *testing.T) {
+		cmd := cmdx.SetCompletionCmd("stencil")
+
+		assert.Contains(t, cmd.Long, "$ source <(stencil completion bash)")
+		assert.NotContains(t, cmd.Long, "%!")
+	})
+
+	t.Run("should reject unknown shell", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil"}
+		root.AddCommand(cmdx.SetCompletionCmd("stencil"))
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs([]string{"completion", "tcsh"})
+
+		err := root.Execute()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,35 @@
+package cmdx
+
+import (
+	"os"
+
+	"github.com/odpf/salt/term"
+)
+
+// Stdin and Stdout are checked to tell if the command runs
+// interactively, they can be replaced e.g. with pipes in tests.
+var (
+	Stdin  = os.Stdin
+	Stdout = os.Stdout
+)
+
+// IsInteractive returns true if both stdin and stdout are terminals,
+// so the user can be prompted for input. Commands should fail instead
+// of prompting when it returns false, e.g. in scripts and pipelines.
+func IsInteractive() bool {
+	return term.IsTerminal(Stdin) && term.IsTerminal(Stdout)
+}
+
+// StdinAvailable returns true if input is piped or redirected to
+// stdin, e.g. `cat schema.json | app create`, so it can be read
+// without blocking on a terminal.
+func StdinAvailable() bool {
+	if term.IsTerminal(Stdin) {
+		return false
+	}
+	info, err := Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
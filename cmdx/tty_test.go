@@ -0,0 +1,72 @@
+package cmdx_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsInteractive(t *testing.T) {
+	withStdio := func(t *testing.T, stdin, stdout *os.File) func() {
+		t.Helper()
+
+		prevIn, prevOut := cmdx.Stdin, cmdx.Stdout
+		cmdx.Stdin, cmdx.Stdout = stdin, stdout
+		return func() {
+			cmdx.Stdin, cmdx.Stdout = prevIn, prevOut
+		}
+	}
+
+	t.Run("should not be interactive with pipes", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		assert.NoError(t, err)
+		defer r.Close()
+		defer w.Close()
+		defer withStdio(t, r, w)()
+
+		assert.False(t, cmdx.IsInteractive())
+	})
+
+	t.Run("should return error when confirming without a terminal", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		assert.NoError(t, err)
+		defer r.Close()
+		defer w.Close()
+		defer withStdio(t, r, w)()
+
+		_, err = cmdx.Confirm("Delete namespace?")
+		assert.ErrorIs(t, err, cmdx.ErrNotInteractive)
+	})
+
+	t.Run("should have stdin available if piped", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		assert.NoError(t, err)
+		defer r.Close()
+		defer w.Close()
+		defer withStdio(t, r, os.Stdout)()
+
+		assert.True(t, cmdx.StdinAvailable())
+	})
+
+	t.Run("should have stdin available if redirected from a file", func(t *testing.T) {
+		f, err := ioutil.TempFile("", "cmdx")
+		assert.NoError(t, err)
+		defer os.Remove(f.Name())
+		defer f.Close()
+		defer withStdio(t, f, os.Stdout)()
+
+		assert.True(t, cmdx.StdinAvailable())
+	})
+
+	t.Run("should not have stdin available if it is a device", func(t *testing.T) {
+		devNull, err := os.Open(os.DevNull)
+		assert.NoError(t, err)
+		defer devNull.Close()
+		defer withStdio(t, devNull, os.Stdout)()
+
+		assert.False(t, cmdx.StdinAvailable())
+	})
+}
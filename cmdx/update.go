@@ -0,0 +1,120 @@
+package cmdx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/odpf/salt/version"
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateCheckInterval is the duration for which the latest
+// release is cached before querying github again.
+var UpdateCheckInterval = 24 * time.Hour
+
+// Release is the latest release of a client on github.
+type Release struct {
+	Version string `yaml:"version"`
+	TarURL  string `yaml:"tar_url"`
+}
+
+type updateState struct {
+	CheckedAt time.Time `yaml:"checked_at"`
+	Latest    Release   `yaml:"latest"`
+}
+
+// CheckForUpdate returns the latest github release of the repo
+// if it is newer than the current version, otherwise nil.
+// The latest release is cached in the odpf config directory
+// so github is queried at most once per UpdateCheckInterval.
+func CheckForUpdate(ctx context.Context, repo string, current string) (*Release, error) {
+	stateFile := updateStateFile(repo)
+
+	state, err := readUpdateState(stateFile)
+	if err != nil || time.Since(state.CheckedAt) > UpdateCheckInterval {
+		latest, err := latestRelease(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		state = &updateState{CheckedAt: time.Now(), Latest: *latest}
+
+		// failing to cache should not fail the update check
+		_ = writeUpdateState(stateFile, state)
+	}
+
+	isCurrentLatest, err := version.IsCurrentLatest(current, state.Latest.Version)
+	if err != nil {
+		return nil, err
+	}
+	if isCurrentLatest {
+		return nil, nil
+	}
+	return &state.Latest, nil
+}
+
+// PrintUpdateNotice prints a notice if a newer release is available.
+func PrintUpdateNotice(w io.Writer, r *Release) {
+	if r == nil {
+		return
+	}
+	fmt.Fprintf(w, "A new release (%s) is available, consider updating the client.\n", r.Version)
+}
+
+func latestRelease(ctx context.Context, repo string) (*Release, error) {
+	type result struct {
+		info *version.Info
+		err  error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		info, err := version.ReleaseInfo(fmt.Sprintf(version.Release, repo))
+		ch <- result{info, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return &Release{Version: r.info.Version, TarURL: r.info.TarURL}, nil
+	}
+}
+
+func updateStateFile(repo string) string {
+	file := strings.ReplaceAll(repo, "/", "-") + ".yml"
+	return filepath.Join(configDir("odpf"), "update", file)
+}
+
+func readUpdateState(filename string) (*updateState, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var state updateState
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func writeUpdateState(filename string, state *updateState) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0600)
+}
@@ -0,0 +1,121 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/odpf/salt/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckForUpdate(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/odpf/not-found" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		response, _ := json.Marshal(struct {
+			TagName string `json:"tag_name"`
+			Tarball string `json:"tarball_url"`
+		}{
+			TagName: "v0.2.0",
+			Tarball: "https://example.com/v0.2.0.tar.gz",
+		})
+		rw.Write(response)
+	}))
+	defer server.Close()
+
+	defaultRelease := version.Release
+	version.Release = server.URL + "/%s"
+	defer func() { version.Release = defaultRelease }()
+
+	setup := func(t *testing.T) func() {
+		t.Helper()
+
+		dir, err := ioutil.TempDir("", "cmdx")
+		assert.NoError(t, err)
+		restore := setenv(t, cmdx.ODPF_CONFIG_DIR, dir)
+		atomic.StoreInt32(&requests, 0)
+		return func() {
+			restore()
+			os.RemoveAll(dir)
+		}
+	}
+
+	t.Run("should return release if newer than current", func(t *testing.T) {
+		defer setup(t)()
+
+		r, err := cmdx.CheckForUpdate(context.Background(), "odpf/stencil", "v0.1.0")
+		assert.NoError(t, err)
+		assert.Equal(t, &cmdx.Release{Version: "v0.2.0", TarURL: "https://example.com/v0.2.0.tar.gz"}, r)
+	})
+
+	t.Run("should return nil if current is the latest", func(t *testing.T) {
+		defer setup(t)()
+
+		r, err := cmdx.CheckForUpdate(context.Background(), "odpf/stencil", "v0.2.0")
+		assert.NoError(t, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("should return nil if current is newer than the latest", func(t *testing.T) {
+		defer setup(t)()
+
+		r, err := cmdx.CheckForUpdate(context.Background(), "odpf/stencil", "v0.3.0")
+		assert.NoError(t, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("should use cached release on subsequent checks", func(t *testing.T) {
+		defer setup(t)()
+
+		_, err := cmdx.CheckForUpdate(context.Background(), "odpf/stencil", "v0.1.0")
+		assert.NoError(t, err)
+		r, err := cmdx.CheckForUpdate(context.Background(), "odpf/stencil", "v0.1.0")
+		assert.NoError(t, err)
+
+		assert.Equal(t, "v0.2.0", r.Version)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("should return error if github returns an error", func(t *testing.T) {
+		defer setup(t)()
+
+		_, err := cmdx.CheckForUpdate(context.Background(), "odpf/not-found", "v0.1.0")
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error if current version is invalid", func(t *testing.T) {
+		defer setup(t)()
+
+		_, err := cmdx.CheckForUpdate(context.Background(), "odpf/stencil", "invalid")
+		assert.Error(t, err)
+	})
+}
+
+func TestPrintUpdateNotice(t *testing.T) {
+	t.Run("should print notice for newer release", func(t *testing.T) {
+		var out bytes.Buffer
+		cmdx.PrintUpdateNotice(&out, &cmdx.Release{Version: "v0.2.0"})
+
+		assert.Equal(t, "A new release (v0.2.0) is available, consider updating the client.\n", out.String())
+	})
+
+	t.Run("should print nothing without a release", func(t *testing.T) {
+		var out bytes.Buffer
+		cmdx.PrintUpdateNotice(&out, nil)
+
+		assert.Empty(t, out.String())
+	})
+}
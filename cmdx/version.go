@@ -0,0 +1,52 @@
+package cmdx
+
+import (
+	"runtime"
+
+	"github.com/odpf/salt/printer"
+	"github.com/spf13/cobra"
+)
+
+// VersionInfo holds the build metadata printed by the version command.
+type VersionInfo struct {
+	Version   string `json:"version" yaml:"version"`
+	Commit    string `json:"commit" yaml:"commit"`
+	BuildDate string `json:"build_date" yaml:"build_date"`
+	GoVersion string `json:"go_version" yaml:"go_version"`
+}
+
+// SetVersionCmd is used to print the build metadata of the client.
+// This should be added on the root command and can
+// be used as `version` or `version --output json`,
+// the output flag is the one of RegisterOutputFlag.
+// GoVersion defaults to the runtime version if not set.
+func SetVersionCmd(info VersionInfo) *cobra.Command {
+	if info.GoVersion == "" {
+		info.GoVersion = runtime.Version()
+	}
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version information",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := GetOutputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			if format != OutputTable {
+				return Print(cmd, info)
+			}
+			return printer.DescriptionList(cmd.OutOrStdout(), [][2]string{
+				{"Version:", info.Version},
+				{"Commit:", info.Commit},
+				{"Build date:", info.BuildDate},
+				{"Go version:", info.GoVersion},
+			})
+		},
+	}
+	// the same flag as the root one if registered there
+	RegisterOutputFlag(cmd)
+
+	return cmd
+}
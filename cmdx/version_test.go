@@ -0,0 +1,74 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionCmd(t *testing.T) {
+	info := cmdx.VersionInfo{
+		Version:   "v0.1.0",
+		Commit:    "4c1fb76",
+		BuildDate: "2021-10-01T00:00:00Z",
+		GoVersion: "go1.16",
+	}
+
+	execute := func(args ...string) (string, error) {
+		root := &cobra.Command{Use: "stencil"}
+		root.AddCommand(cmdx.SetVersionCmd(info))
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	t.Run("should print version information", func(t *testing.T) {
+		out, err := execute("version")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Version:     v0.1.0\nCommit:      4c1fb76\nBuild date:  2021-10-01T00:00:00Z\nGo version:  go1.16\n", out)
+	})
+
+	t.Run("should print version information as json", func(t *testing.T) {
+		out, err := execute("version", "--output", "json")
+		assert.NoError(t, err)
+
+		var got cmdx.VersionInfo
+		assert.NoError(t, json.Unmarshal([]byte(out), &got))
+		assert.Equal(t, info, got)
+		assert.Contains(t, out, `"build_date": "2021-10-01T00:00:00Z"`)
+	})
+
+	t.Run("should return error for unknown output format", func(t *testing.T) {
+		_, err := execute("version", "--output", "xml")
+
+		assert.EqualError(t, err, "invalid output format \"xml\", must be one of table, json or yaml")
+	})
+
+	t.Run("should print version information as yaml", func(t *testing.T) {
+		out, err := execute("version", "-o", "yaml")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "version: v0.1.0\ncommit: 4c1fb76\nbuild_date: \"2021-10-01T00:00:00Z\"\ngo_version: go1.16\n", out)
+	})
+
+	t.Run("should use output flag registered on the root", func(t *testing.T) {
+		root := &cobra.Command{Use: "stencil"}
+		cmdx.RegisterOutputFlag(root)
+		root.AddCommand(cmdx.SetVersionCmd(info))
+
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs([]string{"--output", "json", "version"})
+		assert.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), `"version": "v0.1.0"`)
+	})
+}
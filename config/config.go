@@ -4,15 +4,29 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/jeremywohl/flatten"
 	"github.com/mcuadros/go-defaults"
 	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
+// ErrConfigFileNotFound matches the ConfigFileNotFoundError returned by
+// Load with errors.Is, e.g. to warn instead of failing without a file
+var ErrConfigFileNotFound = errors.New("config file not found")
+
 // ConfigFileNotFoundError is returned when the config file is not found
 // Viper will load from env or defaults
 type ConfigFileNotFoundError struct {
@@ -27,10 +41,86 @@ func (err *ConfigFileNotFoundError) Unwrap() error {
 	return err.err
 }
 
+func (err ConfigFileNotFoundError) Is(target error) bool {
+	return target == ErrConfigFileNotFound
+}
+
+// ConfigParseError is returned when the config file exists
+// but can not be parsed, e.g. because of invalid yaml
+type ConfigParseError struct {
+	File string
+	Err  error
+}
+
+func (err *ConfigParseError) Error() string {
+	return fmt.Sprintf("unable to parse config file %s: %v", err.File, err.Err)
+}
+
+func (err *ConfigParseError) Unwrap() error {
+	return err.Err
+}
+
+const (
+	defaultRemoteTimeout   = 10 * time.Second
+	defaultEncryptedPrefix = "enc:"
+)
+
 type Loader struct {
 	v *viper.Viper
+
+	envPrefix           string
+	envFallbackPrefixes []string
+	envPrefixWarning    func(deprecated, preferred string)
+	envKeyReplacer      *strings.Replacer
+	envNestingSeparator string
+	secretFiles         bool
+	noAutomaticEnv      bool
+	configFileEnv       string
+	configFile          string
+	configName          string
+	configPaths         []string
+	envAliases          map[string]string
+	decryptor           func(ciphertext string) (string, error)
+	encryptedPrefix     string
+	defaulters          []func(config interface{}) error
+	flags               *pflag.FlagSet
+	reloadHandler       func(err error)
+	envExpansion        bool
+	strictEnvExpansion  bool
+	strictKeys          bool
+	validation          bool
+
+	remoteURL     string
+	remoteHeaders map[string]string
+	configMap     map[string]interface{}
+	configDir     string
+
+	// the config file or url read by the last Load
+	configUsed string
+
+	// the settings of the config file and dir read by the
+	// last Load, to tell the source of the values
+	fileSettings      map[string]interface{}
+	configDirSettings map[string]interface{}
+
+	// errors of invalid options returned by Load
+	optionErrs []error
+
+	mu sync.RWMutex
 }
 
+// ValueSource is the source a config value is loaded from
+type ValueSource string
+
+const (
+	SourceDefault   ValueSource = "default"
+	SourceFile      ValueSource = "file"
+	SourceConfigDir ValueSource = "config_dir"
+	SourceConfigMap ValueSource = "config_map"
+	SourceEnv       ValueSource = "env"
+	SourceFlag      ValueSource = "flag"
+)
+
 type LoaderOption func(*Loader)
 
 // WithViper sets the given viper instance for loading configs
@@ -38,6 +128,8 @@ type LoaderOption func(*Loader)
 func WithViper(in *viper.Viper) LoaderOption {
 	return func(l *Loader) {
 		l.v = in
+		l.configFile = in.ConfigFileUsed()
+		l.configName = ""
 	}
 }
 
@@ -45,14 +137,27 @@ func WithViper(in *viper.Viper) LoaderOption {
 // of the config file
 func WithFile(file string) LoaderOption {
 	return func(l *Loader) {
+		l.configFile = file
 		l.v.SetConfigFile(file)
 	}
 }
 
+// WithConfigFileEnv reads the config file at the path set in the
+// environment variable, e.g. `APP_CONFIG=/etc/app/custom.yaml`,
+// instead of searching for it. The file set with WithFile or
+// searched with WithName and WithPath is used if it is not set.
+// The variable is read on every Load, e.g. on reload.
+func WithConfigFileEnv(envName string) LoaderOption {
+	return func(l *Loader) {
+		l.configFileEnv = envName
+	}
+}
+
 // WithName sets the file name of the config file without
 // the extension
 func WithName(in string) LoaderOption {
 	return func(l *Loader) {
+		l.configName = in
 		l.v.SetConfigName(in)
 	}
 }
@@ -61,6 +166,7 @@ func WithName(in string) LoaderOption {
 // can be used multiple times to add multiple paths to search
 func WithPath(in string) LoaderOption {
 	return func(l *Loader) {
+		l.configPaths = append(l.configPaths, in)
 		l.v.AddConfigPath(in)
 	}
 }
@@ -68,9 +174,17 @@ func WithPath(in string) LoaderOption {
 // WithType sets the type of the configuration e.g. "json",
 // "yaml", "hcl"
 // Also used for the extension of the file
+// Load fails if the type is not supported by viper
 func WithType(in string) LoaderOption {
 	return func(l *Loader) {
-		l.v.SetConfigType(in)
+		for _, ext := range viper.SupportedExts {
+			if in == ext {
+				l.v.SetConfigType(in)
+				return
+			}
+		}
+		l.optionErrs = append(l.optionErrs, fmt.Errorf("unsupported config type %q, must be one of: %s",
+			in, strings.Join(viper.SupportedExts, ", ")))
 	}
 }
 
@@ -79,23 +193,207 @@ func WithType(in string) LoaderOption {
 // with `_` in between
 func WithEnvPrefix(in string) LoaderOption {
 	return func(l *Loader) {
+		l.envPrefix = in
 		l.v.SetEnvPrefix(in)
 	}
 }
 
+// WithEnvPrefixes is like WithEnvPrefix but falls back to the variables
+// with the next prefixes, in order, if the variable with the first prefix
+// is not set, e.g. `WithEnvPrefixes("NEW", "OLD")` reads `OLD_PORT` if
+// `NEW_PORT` is not set, to keep accepting a deprecated prefix.
+func WithEnvPrefixes(prefixes ...string) LoaderOption {
+	return func(l *Loader) {
+		if len(prefixes) == 0 {
+			return
+		}
+		WithEnvPrefix(prefixes[0])(l)
+		l.envFallbackPrefixes = prefixes[1:]
+	}
+}
+
+// WithEnvPrefixWarning calls fn during Load for each variable read with
+// a fallback prefix of WithEnvPrefixes, with the name of the variable and
+// of the one it should be renamed to, e.g. to warn about the deprecation.
+func WithEnvPrefixWarning(fn func(deprecated, preferred string)) LoaderOption {
+	return func(l *Loader) {
+		l.envPrefixWarning = fn
+	}
+}
+
 // WithEnvKeyReplacer sets the `old` string to be replaced with
 // the `new` string environmental variable to a key that does
 // not match it.
 func WithEnvKeyReplacer(old string, new string) LoaderOption {
 	return func(l *Loader) {
-		l.v.SetEnvKeyReplacer(strings.NewReplacer(old, new))
+		l.envKeyReplacer = strings.NewReplacer(old, new)
+		l.v.SetEnvKeyReplacer(l.envKeyReplacer)
+	}
+}
+
+// WithEnvKeyNestingSeparator sets the separator used between the
+// prefix and nested keys in environment variables, e.g. with `__`
+// the key `server.read_timeout` is bound to `APP__SERVER__READ_TIMEOUT`
+// so underscores in key names are not mistaken for nesting.
+func WithEnvKeyNestingSeparator(sep string) LoaderOption {
+	return func(l *Loader) {
+		l.envNestingSeparator = sep
+	}
+}
+
+// WithoutAutomaticEnv reads from environment variables only the keys
+// of the config struct, instead of every key viper knows of, e.g. the
+// nested keys of free-form map fields from the config file. An unrelated
+// variable like `PLUGINS_AUTH_URL` then does not override `plugins.auth.url`
+// of the config file.
+func WithoutAutomaticEnv() LoaderOption {
+	return func(l *Loader) {
+		l.noAutomaticEnv = true
+	}
+}
+
+// WithEnvAlias reads the struct key from the environment variable
+// envVar, named as is, instead of the variable derived from the key,
+// e.g. `WithEnvAlias("db.password", "PGPASSWORD")`. It can be used
+// multiple times for the keys the key replacer can not map. The
+// alias takes precedence over the derived variable if both are set.
+func WithEnvAlias(structKey, envVar string) LoaderOption {
+	return func(l *Loader) {
+		if l.envAliases == nil {
+			l.envAliases = map[string]string{}
+		}
+		l.envAliases[strings.ToLower(structKey)] = envVar
+	}
+}
+
+// WithValueDecryptor decrypts the string values prefixed with `enc:`,
+// or the prefix set with WithEncryptedValuePrefix, when loading them
+// into the config struct, e.g. to keep secrets encrypted in the config
+// file. The decryptor is called with the value without the prefix.
+func WithValueDecryptor(decryptor func(ciphertext string) (string, error)) LoaderOption {
+	return func(l *Loader) {
+		l.decryptor = decryptor
+	}
+}
+
+// WithEncryptedValuePrefix sets the prefix of the values decrypted
+// with WithValueDecryptor, `enc:` by default.
+func WithEncryptedValuePrefix(prefix string) LoaderOption {
+	return func(l *Loader) {
+		l.encryptedPrefix = prefix
+	}
+}
+
+// WithDefaulter adds a function setting defaults which can not be
+// expressed with the default struct tag, e.g. paths derived from
+// the user's home. Defaulters run in order after the struct tag
+// defaults, values from the config file and env override both.
+func WithDefaulter(fn func(config interface{}) error) LoaderOption {
+	return func(l *Loader) {
+		l.defaulters = append(l.defaulters, fn)
+	}
+}
+
+// WithFlags binds each flag set by the user to the config key
+// of the same name, flags override env and file.
+func WithFlags(fs *pflag.FlagSet) LoaderOption {
+	return func(l *Loader) {
+		l.flags = fs
+	}
+}
+
+// WithEnvExpansion replaces ${VAR} and $VAR in the config file with
+// the values of the environment variables before parsing it, unset
+// variables are replaced with an empty string. A file searched by a
+// viper set with WithViper is expanded after it is parsed as its
+// search paths are not known.
+func WithEnvExpansion() LoaderOption {
+	return func(l *Loader) {
+		l.envExpansion = true
+	}
+}
+
+// WithStrictEnvExpansion is the same as WithEnvExpansion but Load
+// fails if any of the variables in the config file is not set
+func WithStrictEnvExpansion() LoaderOption {
+	return func(l *Loader) {
+		l.envExpansion = true
+		l.strictEnvExpansion = true
+	}
+}
+
+// WithStrictKeys makes Load fail if the yaml or json config file has
+// keys of the same mapping differing only by case, e.g. `Port` and `port`,
+// which viper silently collapses as keys are case insensitive.
+func WithStrictKeys() LoaderOption {
+	return func(l *Loader) {
+		l.strictKeys = true
+	}
+}
+
+// WithValidation makes Load validate the config once loaded,
+// as done by Validate, and return the error if it is invalid.
+func WithValidation() LoaderOption {
+	return func(l *Loader) {
+		l.validation = true
+	}
+}
+
+// WithSecretFileSupport reads the value of a key from the file
+// referenced by the environment variable of the key suffixed
+// with `_FILE`, e.g. `DB_PASSWORD_FILE=/run/secrets/db_password`.
+// The file contents take precedence over the value of the key
+// in environment variables.
+func WithSecretFileSupport() LoaderOption {
+	return func(l *Loader) {
+		l.secretFiles = true
+	}
+}
+
+// WithRemoteURL fetches the config from the url with a GET request
+// with the headers, e.g. for auth tokens, instead of reading the
+// config file. The config is parsed in the type set with WithType,
+// yaml by default. If the config can not be fetched the config file
+// is read as a fallback, Load fails if there is no config file.
+func WithRemoteURL(url string, headers map[string]string) LoaderOption {
+	return func(l *Loader) {
+		l.remoteURL = url
+		l.remoteHeaders = headers
+	}
+}
+
+// WithConfigMap merges the nested map into the config as if it was
+// read from the config file, overriding the values of the file, e.g.
+// to set exactly the keys needed in tests. A missing config file is
+// not an error with a config map.
+func WithConfigMap(m map[string]interface{}) LoaderOption {
+	return func(l *Loader) {
+		l.configMap = m
+	}
+}
+
+// WithConfigDir reads a key from each file in the directory, e.g. a
+// kubernetes ConfigMap mounted as a directory. The name of the file is
+// the key, with dots for nested keys, e.g. `db.host`, and its trimmed
+// contents the value, converted to the type of the field like env
+// variables, e.g. 0123 is kept as is for a string. The keys override
+// the values of the config file and are overridden by WithConfigMap.
+// Hidden files are skipped. A missing config file is not an error
+// with a config dir. Fields with the `source:"env"` struct tag may be
+// set in it, e.g. from a mounted kubernetes Secret.
+func WithConfigDir(path string) LoaderOption {
+	return func(l *Loader) {
+		l.configDir = path
 	}
 }
 
 // NewLoader returns a config loader with given LoaderOption(s)
 func NewLoader(options ...LoaderOption) *Loader {
 	loader := &Loader{
-		v: getViperWithDefaults(),
+		v:               getViperWithDefaults(),
+		envKeyReplacer:  strings.NewReplacer(".", "_", "-", "_"),
+		encryptedPrefix: defaultEncryptedPrefix,
+		configName:      "config",
 	}
 
 	for _, option := range options {
@@ -107,41 +405,203 @@ func NewLoader(options ...LoaderOption) *Loader {
 // Load loads configuration into the given mapstructure (https://github.com/mitchellh/mapstructure)
 // from a config.yaml file and overrides with any values set in env variables
 func (l *Loader) Load(config interface{}) error {
+	return l.load("", config)
+}
+
+// LoadKey is like Load but loads only the nested key of the config
+// file into the config, e.g. the `database` section into a DBConfig.
+// The fields are bound to env variables of the nested keys, e.g.
+// `DATABASE_HOST` for the field host.
+func (l *Loader) LoadKey(key string, config interface{}) error {
+	if key == "" {
+		return errors.New("key must not be empty")
+	}
+	return l.load(key, config)
+}
+
+func (l *Loader) load(key string, config interface{}) error {
+	prefix := ""
+	if key != "" {
+		prefix = strings.ToLower(key) + "."
+	}
+
 	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
 		return err
 	}
 
-	l.v.AutomaticEnv()
+	if len(l.optionErrs) > 0 {
+		return fmt.Errorf("invalid loader option: %w", l.optionErrs[0])
+	}
+
+	// automatic env takes precedence over explicit bindings and
+	// would join nested keys with the default separator
+	if l.envNestingSeparator == "" && !l.noAutomaticEnv {
+		l.v.AutomaticEnv()
+	}
+
+	// bind only the flags set by the user so flag defaults
+	// do not override the default struct tags
+	if l.flags != nil {
+		var ferr error
+		l.flags.Visit(func(f *pflag.Flag) {
+			if err := l.v.BindPFlag(f.Name, f); err != nil && ferr == nil {
+				ferr = err
+			}
+		})
+		if ferr != nil {
+			return fmt.Errorf("unable to bind flags: %w", ferr)
+		}
+	}
+
+	// resolved on every load so the file of an unset env
+	// variable is not read again, e.g. on reload
+	if l.configFileEnv != "" {
+		file := os.Getenv(l.configFileEnv)
+		if file == "" {
+			file = l.configFile
+		}
+		if file != "" {
+			l.v.SetConfigFile(file)
+		} else {
+			// setting the name makes viper search the file again
+			l.v.SetConfigName(l.configName)
+		}
+	}
 
 	var werr error
 
-	if err := l.v.ReadInConfig(); err != nil {
-		var pathErr = new(fs.PathError)
-		if errors.As(err, &pathErr) || errors.As(err, &viper.ConfigFileNotFoundError{}) {
-			werr = ConfigFileNotFoundError{err}
+	l.configUsed = ""
+	l.fileSettings = nil
+	l.configDirSettings = nil
+
+	var remoteErr error
+	if l.remoteURL != "" {
+		var contents string
+		if contents, remoteErr = l.readRemoteConfig(); remoteErr == nil {
+			l.configUsed = l.remoteURL
+			l.fileSettings = configSettings(contents, l.remoteURL)
+		}
+	}
+	// the local config file is a fallback for the remote config
+	if l.remoteURL == "" || remoteErr != nil {
+		if contents, err := l.readConfigFile(); err != nil {
+			var pathErr = new(fs.PathError)
+			if remoteErr != nil {
+				return fmt.Errorf("unable to read remote config: %w", remoteErr)
+			} else if errors.As(err, &envExpansionError{}) {
+				return err
+			} else if errors.As(err, &pathErr) || errors.Is(err, fs.ErrNotExist) || errors.As(err, &viper.ConfigFileNotFoundError{}) {
+				werr = ConfigFileNotFoundError{err}
+			} else if errors.As(err, &viper.ConfigParseError{}) {
+				return &ConfigParseError{File: l.v.ConfigFileUsed(), Err: err}
+			} else {
+				return fmt.Errorf("unable to read config file: %w", err)
+			}
 		} else {
-			return fmt.Errorf("unable to read config file: %w", err)
+			l.configUsed = l.v.ConfigFileUsed()
+			l.fileSettings = configSettings(contents, l.configUsed)
+			if l.strictKeys {
+				if err := l.checkKeyCase(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// checked before merging the config dir and map which
+	// may set the env only keys
+	if err := l.checkEnvOnlyKeys(config, prefix); err != nil {
+		return err
+	}
+
+	if l.configDir != "" {
+		settings, err := readConfigDir(l.configDir, fieldTypes(reflect.TypeOf(config).Elem(), prefix))
+		if err != nil {
+			return err
+		}
+		l.configDirSettings = settings
+		if err := l.v.MergeConfigMap(settings); err != nil {
+			return fmt.Errorf("unable to merge config dir: %w", err)
 		}
+		werr = nil
+	}
+
+	if l.configMap != nil {
+		if err := l.v.MergeConfigMap(l.configMap); err != nil {
+			return fmt.Errorf("unable to merge config map: %w", err)
+		}
+		werr = nil
 	}
 
 	configKeys, err := getFlattenedStructKeys(config)
 	if err != nil {
 		return fmt.Errorf("unable to get all config keys from struct: %v", err)
 	}
+	for i := range configKeys {
+		configKeys[i] = prefix + configKeys[i]
+	}
 
 	// Bind each conf fields from struct to environment vars
 	for key := range configKeys {
-		if err := l.v.BindEnv(configKeys[key]); err != nil {
+		input := []string{configKeys[key]}
+		if l.envNestingSeparator != "" || l.envAliases[strings.ToLower(configKeys[key])] != "" || len(l.envFallbackPrefixes) > 0 {
+			input = append(input, l.envNames(configKeys[key])...)
+		}
+		if err := l.v.BindEnv(input...); err != nil {
 			return fmt.Errorf("unable to bind env keys: %v", err)
 		}
 	}
 
-	// set defaults using the default struct tag
+	if l.envPrefixWarning != nil {
+		l.warnFallbackEnv(configKeys)
+	}
+
+	l.loadEnvAliases(configKeys)
+
+	if l.secretFiles {
+		if err := l.loadSecretFiles(configKeys); err != nil {
+			return err
+		}
+	}
+
+	if err := l.loadEnvSlices(config, prefix); err != nil {
+		return err
+	}
+
+	// set defaults using the default struct tag and defaulters
 	defaults.SetDefaults(config)
+	for _, defaulter := range l.defaulters {
+		if err := defaulter(config); err != nil {
+			return fmt.Errorf("unable to set defaults: %w", err)
+		}
+	}
+
+	var decoderOpts []viper.DecoderConfigOption
+	if l.decryptor != nil {
+		// decrypt before the values are converted, e.g. to durations
+		decoderOpts = append(decoderOpts, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+			l.decryptHook,
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		)))
+	}
 
-	if err := l.v.Unmarshal(config); err != nil {
+	if key == "" {
+		err = l.v.Unmarshal(config, decoderOpts...)
+	} else {
+		err = l.unmarshalKey(key, config, decoderOpts...)
+	}
+	if err != nil {
 		return fmt.Errorf("unable to load config to struct: %v", err)
 	}
+	// elements of slices and maps exist only after unmarshal
+	setElementDefaults(reflect.ValueOf(config))
+
+	if l.validation {
+		if err := Validate(config); err != nil {
+			return err
+		}
+	}
 
 	if werr != nil {
 		return werr
@@ -150,6 +610,787 @@ func (l *Loader) Load(config interface{}) error {
 	return nil
 }
 
+// MustLoad is like Load but panics if the config can not be loaded.
+// A missing config file is not an error, the config is loaded
+// from env and defaults.
+func (l *Loader) MustLoad(config interface{}) {
+	if err := l.Load(config); err != nil {
+		if errors.As(err, &ConfigFileNotFoundError{}) {
+			return
+		}
+		panic(fmt.Errorf("unable to load config: %w", err))
+	}
+}
+
+// Load loads the config with a loader created with the given options,
+// it is a shorthand for NewLoader(options...).Load(config)
+func Load(config interface{}, options ...LoaderOption) error {
+	return NewLoader(options...).Load(config)
+}
+
+// ConfigFileUsed returns the path of the config file read by Load,
+// or the url with WithRemoteURL. It is empty if no config was read.
+func (l *Loader) ConfigFileUsed() string {
+	return l.configUsed
+}
+
+// Debug returns the source each config value was loaded from,
+// it should be called after Load with the same config.
+func (l *Loader) Debug(config interface{}) (map[string]ValueSource, error) {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		return nil, err
+	}
+
+	configKeys, err := getFlattenedStructKeys(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get all config keys from struct: %v", err)
+	}
+
+	sources := make(map[string]ValueSource, len(configKeys))
+	for _, key := range configKeys {
+		sources[key] = l.source(key)
+	}
+	return sources, nil
+}
+
+// source returns the source of the key in the order of
+// precedence viper resolves values in
+func (l *Loader) source(key string) ValueSource {
+	if l.flags != nil {
+		if f := l.flags.Lookup(key); f != nil && f.Changed {
+			return SourceFlag
+		}
+	}
+
+	for _, name := range l.envNames(key) {
+		if os.Getenv(name) != "" {
+			return SourceEnv
+		}
+	}
+	if l.secretFiles && os.Getenv(l.envName(key)+"_FILE") != "" {
+		return SourceEnv
+	}
+
+	// the config map is merged over the config dir over the file
+	switch {
+	case hasNestedKey(l.configMap, key):
+		return SourceConfigMap
+	case hasNestedKey(l.configDirSettings, key):
+		return SourceConfigDir
+	case l.inConfigFile(key):
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// readConfigFile reads the config file set or searched in the config
+// paths and returns its contents. With env expansion the variables are
+// expanded in the contents before viper parses them, like the remote config.
+func (l *Loader) readConfigFile() (string, error) {
+	file := l.v.ConfigFileUsed()
+	if file == "" && l.configName == "" {
+		// the search paths of a viper set with WithViper
+		// are not known, viper searches the file reading it
+		if err := l.v.ReadInConfig(); err != nil {
+			return "", err
+		}
+		file = l.v.ConfigFileUsed()
+	} else if file == "" {
+		var err error
+		if file, err = l.findConfigFile(); err != nil {
+			return "", err
+		}
+		// viper keeps the searched file as well
+		l.v.SetConfigFile(file)
+	}
+
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	contents := string(raw)
+	if l.envExpansion {
+		if contents, err = l.expand(contents); err != nil {
+			return "", err
+		}
+	}
+	return contents, l.v.ReadConfig(strings.NewReader(contents))
+}
+
+// configSettings returns the nested settings of the config contents in
+// the type of the extension of the file, yaml by default, or nil if they
+// do not parse. They are parsed apart as the ones read by viper are
+// overridden, e.g. by secret files.
+func configSettings(contents, file string) map[string]interface{} {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	ext := strings.TrimPrefix(filepath.Ext(file), ".")
+	for _, supported := range viper.SupportedExts {
+		if ext == supported {
+			v.SetConfigType(ext)
+		}
+	}
+	if err := v.ReadConfig(strings.NewReader(contents)); err != nil {
+		return nil
+	}
+	return v.AllSettings()
+}
+
+// findConfigFile returns the first file with the config name
+// and one of the supported extensions in the config paths, or
+// without extension, in the order viper searches for it
+func (l *Loader) findConfigFile() (string, error) {
+	for _, path := range l.configPaths {
+		files := make([]string, 0, len(viper.SupportedExts)+1)
+		for _, ext := range viper.SupportedExts {
+			files = append(files, filepath.Join(path, l.configName+"."+ext))
+		}
+		files = append(files, filepath.Join(path, l.configName))
+
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return file, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("config file %q not found in %v: %w", l.configName, l.configPaths, fs.ErrNotExist)
+}
+
+// envExpansionError is returned for the env variables
+// of the config which are not set with strict expansion
+type envExpansionError struct {
+	missing []string
+}
+
+func (err envExpansionError) Error() string {
+	return fmt.Sprintf("unable to expand config file, env variables not set: %s", strings.Join(err.missing, ", "))
+}
+
+// expand replaces the environment variables in the config
+func (l *Loader) expand(raw string) (string, error) {
+	var missing []string
+	expanded := os.Expand(raw, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if l.strictEnvExpansion && len(missing) > 0 {
+		return "", envExpansionError{missing: missing}
+	}
+	return expanded, nil
+}
+
+// readRemoteConfig fetches the config from the remote url,
+// reads it in the configured type and returns its contents
+func (l *Loader) readRemoteConfig() (string, error) {
+	req, err := http.NewRequest(http.MethodGet, l.remoteURL, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range l.remoteHeaders {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: defaultRemoteTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status fetching %s: %s", l.remoteURL, res.Status)
+	}
+	raw, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	body := string(raw)
+	if l.envExpansion {
+		if body, err = l.expand(body); err != nil {
+			return "", err
+		}
+	}
+	return body, l.v.ReadConfig(strings.NewReader(body))
+}
+
+// decryptHook replaces the encrypted string values with the plaintext
+func (l *Loader) decryptHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	value, ok := data.(string)
+	if !ok || from.Kind() != reflect.String || !strings.HasPrefix(value, l.encryptedPrefix) {
+		return data, nil
+	}
+
+	plaintext, err := l.decryptor(strings.TrimPrefix(value, l.encryptedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// unmarshalKey unmarshals the nested key into the config, unlike viper's
+// UnmarshalKey the values of the nested keys are overridden from env
+func (l *Loader) unmarshalKey(key string, config interface{}, opts ...viper.DecoderConfigOption) error {
+	var value interface{} = l.v.AllSettings()
+	for _, p := range strings.Split(strings.ToLower(key), ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = m[p]; !ok {
+			return nil
+		}
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("key %s is not a map", key)
+	}
+	sub := viper.New()
+	if err := sub.MergeConfigMap(m); err != nil {
+		return err
+	}
+	return sub.Unmarshal(config, opts...)
+}
+
+// readConfigDir returns the nested settings of the key files in the dir,
+// the files of a mounted ConfigMap are symlinks into a hidden directory.
+// The values are converted to the types of the fields of the keys.
+func readConfigDir(dir string, types map[string]reflect.Type) (map[string]interface{}, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config dir: %w", err)
+	}
+
+	// set on a separate viper to nest the keys, setting
+	// them on the loader would override the env variables
+	v := viper.New()
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read config dir: %w", err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		value, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read config dir file for %s: %w", e.Name(), err)
+		}
+		v.Set(e.Name(), configDirValue(strings.TrimSpace(string(value)), types[strings.ToLower(e.Name())]))
+	}
+	return v.AllSettings(), nil
+}
+
+// configDirValue returns the value converted to the kind of the config
+// field of the key, viper can not merge it otherwise with the number or
+// boolean of the same key in the config file, e.g. 9000 for an int.
+// It is kept as is for the other fields, e.g. strings like 0123, and
+// if it does not parse, to be converted on decoding.
+func configDirValue(value string, t reflect.Type) interface{} {
+	if t == nil || t == reflect.TypeOf(time.Duration(0)) {
+		return value
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// the base is read from the prefix as on decoding
+		if i, err := strconv.ParseInt(value, 0, 64); err == nil {
+			return int(i)
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// fieldTypes returns the types of the fields of the struct type
+// by their keys, the fields of nested structs are keyed by path
+func fieldTypes(t reflect.Type, prefix string) map[string]reflect.Type {
+	types := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")
+		if tag[0] != "" {
+			name = tag[0]
+		}
+		key := strings.ToLower(prefix + name)
+		for _, opt := range tag[1:] {
+			if opt == "squash" {
+				key = strings.TrimSuffix(prefix, ".")
+			}
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct {
+			types[key] = ft
+			continue
+		}
+
+		nestedPrefix := key + "."
+		if key == "" {
+			nestedPrefix = ""
+		}
+		for k, nt := range fieldTypes(ft, nestedPrefix) {
+			types[k] = nt
+		}
+	}
+	return types
+}
+
+func (l *Loader) loadSecretFiles(keys []string) error {
+	for _, key := range keys {
+		file, ok := os.LookupEnv(l.envName(key) + "_FILE")
+		if !ok || file == "" {
+			continue
+		}
+
+		secret, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("unable to read secret file for %s: %w", key, err)
+		}
+		l.v.Set(key, strings.TrimSpace(string(secret)))
+	}
+	return nil
+}
+
+// loadEnvAliases sets the aliased keys from their env variables
+// as automatic env would otherwise read the derived variables first.
+// Flags set by the user still take precedence.
+func (l *Loader) loadEnvAliases(keys []string) {
+	for _, key := range keys {
+		alias, ok := l.envAliases[strings.ToLower(key)]
+		if !ok {
+			continue
+		}
+		value := os.Getenv(alias)
+		if value == "" {
+			continue
+		}
+		if l.flags != nil && l.flags.Changed(key) {
+			continue
+		}
+		l.v.Set(key, value)
+	}
+}
+
+// loadEnvSlices sets the elements of slices of structs from env
+// variables with the index in the key, e.g. `APP_SERVERS_0_HOST`
+// for the key `servers.0.host`, growing the slices loaded from
+// the config file as needed.
+func (l *Loader) loadEnvSlices(config interface{}, prefix string) error {
+	for key, elemType := range structSliceKeys(reflect.TypeOf(config).Elem(), prefix) {
+		fieldKeys, err := getFlattenedStructKeys(reflect.New(elemType).Interface())
+		if err != nil {
+			return fmt.Errorf("unable to get all config keys from struct: %v", err)
+		}
+
+		elems, _ := l.v.Get(key).([]interface{})
+		found := false
+		for i := 0; ; i++ {
+			var elem map[string]interface{}
+			if i < len(elems) {
+				elem = toStringMap(elems[i])
+			}
+
+			elemFound := false
+			for _, fieldKey := range fieldKeys {
+				value, ok := os.LookupEnv(l.envName(fmt.Sprintf("%s.%d.%s", key, i, fieldKey)))
+				if !ok {
+					continue
+				}
+				if elem == nil {
+					elem = map[string]interface{}{}
+				}
+				setNested(elem, strings.Split(strings.ToLower(fieldKey), "."), value)
+				elemFound = true
+			}
+			if !elemFound && i >= len(elems) {
+				break
+			}
+
+			if i < len(elems) {
+				elems[i] = elem
+			} else {
+				elems = append(elems, elem)
+			}
+			found = found || elemFound
+		}
+
+		// merged into the config read on every load, setting it
+		// would override the config file on the next load
+		if found {
+			settings := map[string]interface{}{}
+			setNested(settings, strings.Split(key, "."), elems)
+			if err := l.v.MergeConfigMap(settings); err != nil {
+				return fmt.Errorf("unable to merge env slices: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// structSliceKeys returns the keys of the fields of t which are slices
+// of structs, along with the type of the struct
+func structSliceKeys(t reflect.Type, prefix string) map[string]reflect.Type {
+	keys := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")
+		if tag[0] != "" {
+			name = tag[0]
+		}
+		key := strings.ToLower(prefix + name)
+		for _, opt := range tag[1:] {
+			if opt == "squash" {
+				key = strings.TrimSuffix(prefix, ".")
+			}
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct:
+			nestedPrefix := key + "."
+			if key == "" {
+				nestedPrefix = ""
+			}
+			for k, v := range structSliceKeys(ft, nestedPrefix) {
+				keys[k] = v
+			}
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct:
+			keys[key] = ft.Elem()
+		}
+	}
+	return keys
+}
+
+// checkEnvOnlyKeys returns an error if any of the fields with the
+// `source:"env"` struct tag is set in the config file, e.g. secrets
+// which must not be committed with the config file. The config dir
+// and the config map are not checked, they are not committed files.
+func (l *Loader) checkEnvOnlyKeys(config interface{}, prefix string) error {
+	var inFile []string
+	for _, key := range envOnlyKeys(reflect.TypeOf(config).Elem(), prefix) {
+		if l.inConfigFile(key) {
+			inFile = append(inFile, fmt.Sprintf("%s (%s)", key, l.envName(key)))
+		}
+	}
+	if len(inFile) > 0 {
+		sort.Strings(inFile)
+		return fmt.Errorf("keys must be set in env variables instead of the config file: %s", strings.Join(inFile, ", "))
+	}
+	return nil
+}
+
+// checkKeyCase returns an error naming the keys of the config file
+// colliding with another key of the same mapping under case folding
+func (l *Loader) checkKeyCase() error {
+	raw, err := ioutil.ReadFile(l.v.ConfigFileUsed())
+	if err != nil {
+		return fmt.Errorf("unable to read config file: %w", err)
+	}
+
+	// json is parsed as yaml, other formats have no mappings
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil
+	}
+
+	collisions := caseCollisions(&node, "")
+	if len(collisions) > 0 {
+		return fmt.Errorf("keys differing only by case in the config file: %s", strings.Join(collisions, ", "))
+	}
+	return nil
+}
+
+// caseCollisions returns the paths of the keys of the nested mappings
+// of the node which collide under case folding, e.g. `db.{Host,host}`
+func caseCollisions(node *yaml.Node, path string) []string {
+	var collisions []string
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			collisions = append(collisions, caseCollisions(n, path)...)
+		}
+	case yaml.SequenceNode:
+		for i, n := range node.Content {
+			collisions = append(collisions, caseCollisions(n, fmt.Sprintf("%s%d.", path, i))...)
+		}
+	case yaml.MappingNode:
+		var folded []string
+		keys := map[string][]string{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			f := strings.ToLower(key)
+			if _, ok := keys[f]; !ok {
+				folded = append(folded, f)
+			}
+			keys[f] = append(keys[f], key)
+			collisions = append(collisions, caseCollisions(node.Content[i+1], path+f+".")...)
+		}
+		for _, f := range folded {
+			if len(keys[f]) > 1 {
+				collisions = append(collisions, fmt.Sprintf("%s{%s}", path, strings.Join(keys[f], ",")))
+			}
+		}
+	}
+	return collisions
+}
+
+// inConfigFile returns true if the key is set in the config file or
+// remote config read by the last Load, unlike viper's InConfig it
+// supports nested keys
+func (l *Loader) inConfigFile(key string) bool {
+	return hasNestedKey(l.fileSettings, key)
+}
+
+// hasNestedKey returns true if the dot separated key is
+// set in the nested settings, the keys are case insensitive
+func hasNestedKey(settings map[string]interface{}, key string) bool {
+	var value interface{} = settings
+	for _, p := range strings.Split(key, ".") {
+		found := false
+		switch m := value.(type) {
+		case map[string]interface{}:
+			for k, v := range m {
+				if strings.EqualFold(k, p) {
+					value, found = v, true
+					break
+				}
+			}
+		case map[interface{}]interface{}:
+			for k, v := range m {
+				if strings.EqualFold(fmt.Sprint(k), p) {
+					value, found = v, true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// envOnlyKeys returns the keys of the fields of the struct
+// type with the `source:"env"` struct tag
+func envOnlyKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")
+		if tag[0] != "" {
+			name = tag[0]
+		}
+		key := strings.ToLower(prefix + name)
+		for _, opt := range tag[1:] {
+			if opt == "squash" {
+				key = strings.TrimSuffix(prefix, ".")
+			}
+		}
+
+		if field.Tag.Get("source") == "env" {
+			keys = append(keys, key)
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			nestedPrefix := key + "."
+			if key == "" {
+				nestedPrefix = ""
+			}
+			keys = append(keys, envOnlyKeys(ft, nestedPrefix)...)
+		}
+	}
+	return keys
+}
+
+// setElementDefaults sets the default struct tags of the struct
+// elements of slices and maps in v, for fields which are not set
+func setElementDefaults(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			setElementDefaults(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				setElementDefaults(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			setZeroFieldDefaults(v.Index(i))
+			setElementDefaults(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// map values are not addressable, update a copy
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			setZeroFieldDefaults(elem)
+			setElementDefaults(elem)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
+
+// setZeroFieldDefaults sets the zero fields of the struct, or pointer
+// to struct, v to their defaults
+func setZeroFieldDefaults(v reflect.Value) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	defaultValue := reflect.New(v.Type())
+	defaults.SetDefaults(defaultValue.Interface())
+	mergeZeroFields(v, defaultValue.Elem())
+}
+
+func mergeZeroFields(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		field := dst.Field(i)
+		switch {
+		case field.IsZero():
+			field.Set(src.Field(i))
+		case field.Kind() == reflect.Struct:
+			mergeZeroFields(field, src.Field(i))
+		}
+	}
+}
+
+func toStringMap(in interface{}) map[string]interface{} {
+	switch m := in.(type) {
+	case map[string]interface{}:
+		return m
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[fmt.Sprint(k)] = v
+		}
+		return out
+	}
+	return nil
+}
+
+func setNested(m map[string]interface{}, path []string, value interface{}) {
+	for _, p := range path[:len(path)-1] {
+		next := toStringMap(m[p])
+		if next == nil {
+			next = map[string]interface{}{}
+		}
+		m[p] = next
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// envName returns the environment variable viper binds the key to
+func (l *Loader) envName(key string) string {
+	if alias, ok := l.envAliases[strings.ToLower(key)]; ok {
+		return alias
+	}
+	return l.prefixedEnvName(l.envPrefix, key)
+}
+
+// envNames returns the env variable of the key followed by the
+// ones with the fallback prefixes, in the order they are read
+func (l *Loader) envNames(key string) []string {
+	names := []string{l.envName(key)}
+	if _, ok := l.envAliases[strings.ToLower(key)]; ok {
+		return names
+	}
+	for _, prefix := range l.envFallbackPrefixes {
+		names = append(names, l.prefixedEnvName(prefix, key))
+	}
+	return names
+}
+
+func (l *Loader) prefixedEnvName(prefix, key string) string {
+	sep := "_"
+	if l.envNestingSeparator != "" {
+		sep = l.envNestingSeparator
+		key = strings.ReplaceAll(key, ".", sep)
+	}
+
+	name := key
+	if prefix != "" {
+		name = prefix + sep + name
+	}
+	name = strings.ToUpper(name)
+	if l.envKeyReplacer != nil {
+		name = l.envKeyReplacer.Replace(name)
+	}
+	return name
+}
+
+// warnFallbackEnv calls the env prefix warning for the keys
+// read from a variable with a fallback prefix
+func (l *Loader) warnFallbackEnv(keys []string) {
+	for _, key := range keys {
+		names := l.envNames(key)
+		if _, ok := os.LookupEnv(names[0]); ok {
+			continue
+		}
+		for _, name := range names[1:] {
+			if _, ok := os.LookupEnv(name); ok {
+				l.envPrefixWarning(name, names[0])
+				break
+			}
+		}
+	}
+}
+
 func verifyParamIsPtrToStructElsePanic(param interface{}) error {
 	value := reflect.ValueOf(param)
 	if value.Kind() != reflect.Ptr {
@@ -167,7 +1408,7 @@ func getViperWithDefaults() *viper.Viper {
 	v := viper.New()
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 	return v
 }
 
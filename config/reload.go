@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+)
+
+// WithReloadHandler sets the function called after each reload
+// triggered by ReloadOnSignal with the error of the reload, if any.
+func WithReloadHandler(fn func(err error)) LoaderOption {
+	return func(l *Loader) {
+		l.reloadHandler = fn
+	}
+}
+
+// ReloadOnSignal loads the config again into the given struct each
+// time one of the signals is received, SIGHUP if none is given.
+// The config is loaded into a new struct first and swapped only if
+// loading succeeds, so a broken config file keeps the current values.
+// Code reading the config concurrently must hold the read lock:
+//     l.RLock()
+//     port := cfg.Port
+//     l.RUnlock()
+// The returned function stops reloading.
+func (l *Loader) ReloadOnSignal(config interface{}, sig ...os.Signal) (stop func()) {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		panic(err)
+	}
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sig...)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+				err := l.reload(config)
+				if l.reloadHandler != nil {
+					l.reloadHandler(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// RLock locks the config for reading while it may be reloaded
+func (l *Loader) RLock() {
+	l.mu.RLock()
+}
+
+// RUnlock undoes a single RLock call
+func (l *Loader) RUnlock() {
+	l.mu.RUnlock()
+}
+
+func (l *Loader) reload(config interface{}) error {
+	fresh := reflect.New(reflect.TypeOf(config).Elem())
+	if err := l.Load(fresh.Interface()); err != nil && !errors.As(err, &ConfigFileNotFoundError{}) {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	reflect.ValueOf(config).Elem().Set(fresh.Elem())
+	return nil
+}
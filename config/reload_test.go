@@ -0,0 +1,73 @@
+//go:build !windows
+// +build !windows
+
+package config_test
+
+import (
+	"io/ioutil"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	file := writeFile(t, dir, "config.yaml", "port: 9000\n")
+
+	reloaded := make(chan error, 1)
+	var cfg testConfig
+	l := config.NewLoader(
+		config.WithFile(file),
+		config.WithReloadHandler(func(err error) {
+			reloaded <- err
+		}),
+	)
+	assert.NoError(t, l.Load(&cfg))
+
+	stop := l.ReloadOnSignal(&cfg, syscall.SIGUSR1)
+	defer stop()
+
+	waitReload := func(t *testing.T) error {
+		t.Helper()
+
+		assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+		select {
+		case err := <-reloaded:
+			return err
+		case <-time.After(time.Second):
+			t.Fatal("config not reloaded")
+			return nil
+		}
+	}
+
+	t.Run("should update config on signal", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(file, []byte("port: 9001\n"), 0600))
+		assert.NoError(t, waitReload(t))
+
+		l.RLock()
+		defer l.RUnlock()
+		assert.Equal(t, 9001, cfg.Port)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+	})
+
+	t.Run("should keep config if reload fails", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(file, []byte("port: [invalid\n"), 0600))
+		assert.Error(t, waitReload(t))
+
+		l.RLock()
+		defer l.RUnlock()
+		assert.Equal(t, 9001, cfg.Port)
+	})
+
+	t.Run("should be safe to stop multiple times", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			stop()
+			stop()
+		})
+	})
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mcuadros/go-defaults"
+	"gopkg.in/yaml.v3"
+)
+
+const redactedValue = "*****"
+
+// Sample returns a yaml config file with the values of the config
+// struct, after setting its defaults, keyed by the mapstructure tags
+// the config is loaded with. The desc tags of the fields are added
+// as comments, e.g. to write a starter config for users to edit.
+func Sample(config interface{}) ([]byte, error) {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		return nil, err
+	}
+	defaults.SetDefaults(config)
+
+	out, err := encodeNode(reflect.ValueOf(config).Elem(), false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate sample config: %w", err)
+	}
+	return out, nil
+}
+
+// Redacted returns the values of the config struct as yaml keyed by
+// the mapstructure tags, like Sample without setting the defaults,
+// with the values of the fields tagged `secret:"true"` or
+// `source:"env"` masked, e.g. to print the effective config in
+// diagnostics. Unset secrets are left empty.
+func Redacted(config interface{}) ([]byte, error) {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		return nil, err
+	}
+
+	out, err := encodeNode(reflect.ValueOf(config).Elem(), true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to redact config: %w", err)
+	}
+	return out, nil
+}
+
+func encodeNode(v reflect.Value, redact bool) ([]byte, error) {
+	node, err := sampleNode(v, redact)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	enc := yaml.NewEncoder(&sb)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(sb.String()), nil
+}
+
+func sampleNode(v reflect.Value, redact bool) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")
+		key := tag[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+
+		value := &yaml.Node{}
+		var err error
+		if field.Type.Kind() == reflect.Struct {
+			value, err = sampleNode(v.Field(i), redact)
+		} else if redact && IsSecret(field) && !v.Field(i).IsZero() {
+			err = value.Encode(redactedValue)
+		} else {
+			value, err = valueNode(v.Field(i), redact)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// squashed fields are at the same level as the parent
+		if len(tag) > 1 && tag[1] == "squash" && value.Kind == yaml.MappingNode {
+			node.Content = append(node.Content, value.Content...)
+			continue
+		}
+
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+		if !redact {
+			keyNode.HeadComment = field.Tag.Get("desc")
+		}
+		node.Content = append(node.Content, keyNode, value)
+	}
+	return node, nil
+}
+
+// valueNode returns the node of a field value, with the structs in
+// pointers, slices, arrays and maps keyed by their mapstructure tags
+// like the fields of the config struct, so their secrets are masked
+func valueNode(v reflect.Value, redact bool) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if !hasStruct(v.Type()) {
+		return node, node.Encode(v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return sampleNode(v, redact)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return node, node.Encode(nil)
+		}
+		return valueNode(v.Elem(), redact)
+	case reflect.Slice, reflect.Array:
+		node.Kind = yaml.SequenceNode
+		for i := 0; i < v.Len(); i++ {
+			elem, err := valueNode(v.Index(i), redact)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, elem)
+		}
+	case reflect.Map:
+		node.Kind = yaml.MappingNode
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			keyNode := &yaml.Node{}
+			if err := keyNode.Encode(k.Interface()); err != nil {
+				return nil, err
+			}
+			elem, err := valueNode(v.MapIndex(k), redact)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode, elem)
+		}
+	}
+
+	// empty like the yaml encoding, e.g. `[]` and not a blank value
+	if len(node.Content) == 0 {
+		node.Style = yaml.FlowStyle
+	}
+	return node, nil
+}
+
+// hasStruct reports whether the values of the type hold structs,
+// directly or in pointers, slices, arrays or maps
+func hasStruct(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasStruct(t.Elem())
+	}
+	return false
+}
+
+// IsSecret reports whether the value of the struct field is a secret,
+// i.e. tagged `secret:"true"` or `source:"env"`, which Redacted masks
+func IsSecret(field reflect.StructField) bool {
+	return field.Tag.Get("secret") == "true" || field.Tag.Get("source") == "env"
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Validator is implemented by configs checking rules the validate
+// struct tags can not express, e.g. rules across fields
+type Validator interface {
+	Validate() error
+}
+
+var validate = newValidate()
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	// name the fields by their config keys in the errors
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return strings.ToLower(f.Name)
+		}
+		return name
+	})
+	return v
+}
+
+// Validate checks the config against the validate struct tags of its
+// fields, e.g. `validate:"required,min=1"`, see the tags supported at
+// https://pkg.go.dev/github.com/go-playground/validator/v10, and calls
+// its Validate method if the config is a Validator. Load validates the
+// config once loaded with WithValidation, Validate can be called on its
+// own, e.g. after changing the config.
+func Validate(config interface{}) error {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		return err
+	}
+
+	if err := validate.Struct(config); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+
+		msgs := make([]string, 0, len(verrs))
+		for _, e := range verrs {
+			rule := e.Tag()
+			if e.Param() != "" {
+				rule += "=" + e.Param()
+			}
+			// the namespace starts with the name of the struct
+			key := e.Namespace()
+			if i := strings.Index(key, "."); i >= 0 {
+				key = key[i+1:]
+			}
+			msgs = append(msgs, fmt.Sprintf("%s failed on %s", key, rule))
+		}
+		return fmt.Errorf("invalid config: %s", strings.Join(msgs, ", "))
+	}
+
+	if v, ok := config.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+type asyncEntry struct {
+	line    []byte
+	flushed chan struct{}
+}
+
+// asyncWriter writes lines to the underlying writer in the
+// background so slow writers do not block the callers.
+type asyncWriter struct {
+	mu         sync.RWMutex
+	out        io.Writer
+	entries    chan asyncEntry
+	dropOnFull bool
+	closed     bool
+	done       chan struct{}
+}
+
+func newAsyncWriter(out io.Writer, bufSize int, dropOnFull bool) *asyncWriter {
+	w := &asyncWriter{
+		out:        out,
+		entries:    make(chan asyncEntry, bufSize),
+		dropOnFull: dropOnFull,
+		done:       make(chan struct{}),
+	}
+	go w.drain()
+	return w
+}
+
+func (w *asyncWriter) drain() {
+	defer close(w.done)
+	for e := range w.entries {
+		if e.flushed != nil {
+			close(e.flushed)
+			continue
+		}
+		if _, err := w.out.Write(e.line); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+		}
+	}
+}
+
+// Write queues a copy of p, it drops p if the buffer is full
+// and dropOnFull is set, or blocks until there is space.
+// Lines written after Close are written synchronously.
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return w.out.Write(p)
+	}
+
+	// the caller may reuse p once Write returns
+	e := asyncEntry{line: append([]byte(nil), p...)}
+	if w.dropOnFull {
+		select {
+		case w.entries <- e:
+		default:
+		}
+		return len(p), nil
+	}
+	w.entries <- e
+	return len(p), nil
+}
+
+// Flush blocks until the lines queued before it are written.
+func (w *asyncWriter) Flush() {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return
+	}
+	flushed := make(chan struct{})
+	w.entries <- asyncEntry{flushed: flushed}
+	<-flushed
+}
+
+// Close writes the queued lines and stops the background writer.
+func (w *asyncWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+	w.closed = true
+	close(w.entries)
+	<-w.done
+}
@@ -0,0 +1,33 @@
+package log
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+type callerMarker struct{}
+
+var (
+	logPackage    = reflect.TypeOf(callerMarker{}).PkgPath() + "."
+	logrusPackage = "github.com/sirupsen/logrus."
+)
+
+// caller returns the file and line of the first frame outside
+// of this package and logrus, trimmed to `pkg/file.go:line`.
+func caller() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, logPackage) && !strings.HasPrefix(frame.Function, logrusPackage) {
+			return fmt.Sprintf("%s/%s:%d", path.Base(path.Dir(frame.File)), path.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/odpf/salt/term"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	colorRed     = 31
+	colorGreen   = 32
+	colorYellow  = 33
+	colorMagenta = 35
+	colorCyan    = 36
+
+	consoleMessageWidth = 40
+)
+
+// ConsoleFormatter formats logs for humans reading them in the
+// terminal, e.g. in local development:
+//   15:04:05.000 INF request served    method=GET path=/ping
+// The level is colored and the fields are dimmed if the logs are
+// written to a terminal, fields are sorted by key.
+type ConsoleFormatter struct {
+	// TimestampFormat defaults to 15:04:05.000
+	TimestampFormat string
+
+	DisableTimestamp bool
+
+	// ForceColors colors the output even if it is not a terminal
+	ForceColors bool
+}
+
+func (f *ConsoleFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	colors := f.ForceColors
+	if !colors && entry.Logger != nil {
+		colors = term.IsTerminal(entry.Logger.Out) && !term.IsColorDisabled()
+	}
+
+	var b bytes.Buffer
+	if !f.DisableTimestamp {
+		layout := f.TimestampFormat
+		if layout == "" {
+			layout = "15:04:05.000"
+		}
+		b.WriteString(entry.Time.Format(layout))
+		b.WriteByte(' ')
+	}
+
+	level := consoleLevel(entry.Level)
+	if colors {
+		level = fmt.Sprintf("\x1b[%dm%s\x1b[0m", consoleLevelColor(entry.Level), level)
+	}
+	b.WriteString(level)
+	b.WriteByte(' ')
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if len(keys) == 0 {
+		b.WriteString(entry.Message)
+		b.WriteByte('\n')
+		return b.Bytes(), nil
+	}
+
+	fmt.Fprintf(&b, "%-*s", consoleMessageWidth, entry.Message)
+	for _, k := range keys {
+		field := fmt.Sprintf("%s=%s", k, consoleValue(entry.Data[k]))
+		if colors {
+			field = "\x1b[2m" + field + "\x1b[0m"
+		}
+		b.WriteByte(' ')
+		b.WriteString(field)
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func consoleLevel(level logrus.Level) string {
+	switch level {
+	case logrus.TraceLevel:
+		return "TRC"
+	case logrus.DebugLevel:
+		return "DBG"
+	case logrus.InfoLevel:
+		return "INF"
+	case logrus.WarnLevel:
+		return "WRN"
+	case logrus.ErrorLevel:
+		return "ERR"
+	case logrus.FatalLevel:
+		return "FTL"
+	default:
+		return "PNC"
+	}
+}
+
+func consoleLevelColor(level logrus.Level) int {
+	switch level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return colorMagenta
+	case logrus.InfoLevel:
+		return colorGreen
+	case logrus.WarnLevel:
+		return colorYellow
+	case logrus.ErrorLevel:
+		return colorRed
+	default:
+		return colorCyan
+	}
+}
+
+// consoleValue quotes values with spaces so fields stay readable
+func consoleValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if err, ok := v.(error); ok {
+		s = err.Error()
+	}
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleFormatter(t *testing.T) {
+	newEntry := func(level logrus.Level, msg string, fields logrus.Fields) *logrus.Entry {
+		entry := logrus.NewEntry(logrus.New())
+		entry.Logger.Out = &bytes.Buffer{}
+		entry.Time = time.Date(2021, 10, 1, 8, 30, 0, 0, time.UTC)
+		entry.Level = level
+		entry.Message = msg
+		entry.Data = fields
+		return entry
+	}
+
+	t.Run("should write timestamp, level, message and sorted fields", func(t *testing.T) {
+		f := &log.ConsoleFormatter{}
+		out, err := f.Format(newEntry(logrus.InfoLevel, "request served", logrus.Fields{
+			"path":   "/ping",
+			"method": "GET",
+			"error":  errors.New("timed out"),
+			"user":   "john doe",
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("08:30:00.000 INF %-40s %s\n", "request served",
+			`error="timed out" method=GET path=/ping user="john doe"`), string(out))
+	})
+
+	t.Run("should not pad message without fields", func(t *testing.T) {
+		f := &log.ConsoleFormatter{DisableTimestamp: true}
+		out, err := f.Format(newEntry(logrus.WarnLevel, "slow request", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, "WRN slow request\n", string(out))
+	})
+
+	t.Run("should not color output if writer is not a terminal", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := log.NewLogrus(log.LogrusWithWriter(&b), log.LogrusWithConsoleFormat())
+		logger.Error("request failed", "path", "/ping")
+
+		assert.NotContains(t, b.String(), "\x1b[")
+		assert.Contains(t, b.String(), " ERR request failed")
+	})
+
+	t.Run("should color level and dim fields if forced", func(t *testing.T) {
+		f := &log.ConsoleFormatter{DisableTimestamp: true, ForceColors: true}
+		out, err := f.Format(newEntry(logrus.ErrorLevel, "request failed", logrus.Fields{"path": "/ping"}))
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("\x1b[31mERR\x1b[0m %-40s \x1b[2mpath=/ping\x1b[0m\n", "request failed"), string(out))
+	})
+}
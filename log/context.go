@@ -0,0 +1,20 @@
+package log
+
+import "context"
+
+type loggerContextKey struct{}
+
+// NewContext returns a context carrying the logger,
+// retrieved by the handlers with FromContext.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger carried by the context,
+// or a no operation logger if the context has none.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(Logger); ok {
+		return l
+	}
+	return NewNoop()
+}
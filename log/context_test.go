@@ -0,0 +1,22 @@
+package log_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/odpf/salt/log"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Run("should return logger of the context", func(t *testing.T) {
+		logger := log.NewLogrus()
+		ctx := log.NewContext(context.Background(), logger)
+		assert.Equal(t, logger, log.FromContext(ctx))
+	})
+
+	t.Run("should return noop logger without logger in context", func(t *testing.T) {
+		assert.IsType(t, &log.Noop{}, log.FromContext(context.Background()))
+	})
+}
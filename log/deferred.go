@@ -0,0 +1,179 @@
+package log
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// DeferredLogger buffers the logs made before the real logger is
+// configured, e.g. while reading the config at startup, and replays
+// them in order into the logger set with Attach. Logs after Attach
+// are forwarded to it. Loggers returned by WithFields share the buffer.
+// The buffer is unbounded, so Attach should be called early.
+type DeferredLogger struct {
+	state  *deferredState
+	fields map[string]interface{}
+}
+
+type deferredState struct {
+	mu      sync.Mutex
+	logger  Logger
+	entries []deferredEntry
+}
+
+type deferredEntry struct {
+	level  string
+	msg    string
+	args   []interface{}
+	fields map[string]interface{}
+}
+
+// NewDeferredLogger returns a logger buffering logs until Attach
+func NewDeferredLogger() *DeferredLogger {
+	return &DeferredLogger{state: &deferredState{}}
+}
+
+// Attach replays the buffered logs into l, with their fields,
+// and forwards the later logs to it
+func (d *DeferredLogger) Attach(l Logger) {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+
+	for _, e := range d.state.entries {
+		replay(l, e)
+	}
+	d.state.entries = nil
+	d.state.logger = l
+}
+
+func replay(l Logger, e deferredEntry) {
+	if len(e.fields) > 0 {
+		l = l.WithFields(e.fields)
+	}
+
+	switch e.level {
+	case "debug":
+		l.Debug(e.msg, e.args...)
+	case "info":
+		l.Info(e.msg, e.args...)
+	case "warn":
+		l.Warn(e.msg, e.args...)
+	case "error":
+		l.Error(e.msg, e.args...)
+	case "fatal":
+		l.Fatal(e.msg, e.args...)
+	}
+}
+
+// log buffers the entry, or returns the attached
+// logger with the fields to log it to
+func (d *DeferredLogger) log(e deferredEntry) Logger {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+
+	if d.state.logger == nil {
+		e.fields = d.fields
+		d.state.entries = append(d.state.entries, e)
+		return nil
+	}
+	if len(d.fields) > 0 {
+		return d.state.logger.WithFields(d.fields)
+	}
+	return d.state.logger
+}
+
+func (d *DeferredLogger) Debug(msg string, args ...interface{}) {
+	if l := d.log(deferredEntry{level: "debug", msg: msg, args: args}); l != nil {
+		l.Debug(msg, args...)
+	}
+}
+
+func (d *DeferredLogger) Info(msg string, args ...interface{}) {
+	if l := d.log(deferredEntry{level: "info", msg: msg, args: args}); l != nil {
+		l.Info(msg, args...)
+	}
+}
+
+func (d *DeferredLogger) Warn(msg string, args ...interface{}) {
+	if l := d.log(deferredEntry{level: "warn", msg: msg, args: args}); l != nil {
+		l.Warn(msg, args...)
+	}
+}
+
+func (d *DeferredLogger) Error(msg string, args ...interface{}) {
+	if l := d.log(deferredEntry{level: "error", msg: msg, args: args}); l != nil {
+		l.Error(msg, args...)
+	}
+}
+
+// Fatal logs the message and exits, before Attach the buffered
+// logs are replayed into a default Logrus logger to exit with it
+func (d *DeferredLogger) Fatal(msg string, args ...interface{}) {
+	if l := d.log(deferredEntry{level: "fatal", msg: msg, args: args}); l != nil {
+		l.Fatal(msg, args...)
+		return
+	}
+	d.Attach(NewLogrus())
+}
+
+// Level returns the level of the attached logger,
+// debug before Attach as every log is buffered
+func (d *DeferredLogger) Level() string {
+	if l := d.attached(); l != nil {
+		return l.Level()
+	}
+	return "debug"
+}
+
+// Enabled returns true for every level before Attach, the
+// attached logger filters the buffered logs when replayed
+func (d *DeferredLogger) Enabled(level string) bool {
+	if l := d.attached(); l != nil {
+		return l.Enabled(level)
+	}
+	return true
+}
+
+// Writer returns a writer logging each line written to
+// it at info level, e.g. to redirect the standard logger
+func (d *DeferredLogger) Writer() io.Writer {
+	return deferredWriter{d}
+}
+
+func (d *DeferredLogger) WithFields(fields map[string]interface{}) Logger {
+	merged := make(map[string]interface{}, len(d.fields)+len(fields))
+	for k, v := range d.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &DeferredLogger{state: d.state, fields: merged}
+}
+
+// Close closes the attached logger, it
+// is a no-op if no logger is attached
+func (d *DeferredLogger) Close() error {
+	if l := d.attached(); l != nil {
+		return l.Close()
+	}
+	return nil
+}
+
+func (d *DeferredLogger) attached() Logger {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	return d.state.logger
+}
+
+type deferredWriter struct {
+	d *DeferredLogger
+}
+
+func (w deferredWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimSuffix(string(p), "\n"), "\n") {
+		w.d.Info(line)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,81 @@
+package log_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/odpf/salt/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeferredLogger(t *testing.T) {
+	newLogrus := func(b *bytes.Buffer) *log.Logrus {
+		return log.NewLogrus(log.LogrusWithLevel("info"), log.LogrusWithWriter(b), log.LogrusWithFormatter(&logrus.TextFormatter{
+			DisableTimestamp: true,
+		}))
+	}
+
+	t.Run("should replay buffered logs in order after attach", func(t *testing.T) {
+		d := log.NewDeferredLogger()
+		d.Info("reading config", "path", "config.yaml")
+		d.WithFields(map[string]interface{}{"component": "db"}).Warn("no password set")
+		d.Debug("filtered by the attached logger")
+		fmt.Fprint(d.Writer(), "raw line\n")
+
+		var b bytes.Buffer
+		d.Attach(newLogrus(&b))
+
+		assert.Equal(t, "level=info msg=\"reading config\" path=config.yaml\n"+
+			"level=warning msg=\"no password set\" component=db\n"+
+			"level=info msg=\"raw line\"\n", b.String())
+	})
+
+	t.Run("should not write before attach", func(t *testing.T) {
+		var b bytes.Buffer
+		l := newLogrus(&b)
+
+		d := log.NewDeferredLogger()
+		d.Error("failed")
+		assert.Empty(t, b.String())
+		assert.True(t, d.Enabled("debug"))
+
+		d.Attach(l)
+		assert.Equal(t, "level=error msg=failed\n", b.String())
+	})
+
+	t.Run("should forward logs after attach", func(t *testing.T) {
+		d := log.NewDeferredLogger()
+		child := d.WithFields(map[string]interface{}{"component": "server"})
+		d.Info("first")
+
+		var b bytes.Buffer
+		d.Attach(newLogrus(&b))
+		child.Info("second")
+		d.Info("third")
+
+		assert.Equal(t, "level=info msg=first\n"+
+			"level=info msg=second component=server\n"+
+			"level=info msg=third\n", b.String())
+		assert.Equal(t, "info", d.Level())
+		assert.False(t, d.Enabled("debug"))
+	})
+
+	t.Run("should close the attached logger", func(t *testing.T) {
+		d := log.NewDeferredLogger()
+		assert.NoError(t, d.Close())
+
+		var b bytes.Buffer
+		w := bufio.NewWriter(&b)
+		d.Info("started")
+		d.Attach(log.NewLogrus(log.LogrusWithWriter(w), log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true})))
+		assert.Empty(t, b.String())
+
+		assert.NoError(t, d.Close())
+		assert.Equal(t, "level=info msg=started\n", b.String())
+	})
+}
@@ -0,0 +1,161 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// fielder is an argument expanding into multiple key/value pairs
+type fielder interface {
+	fields() []interface{}
+}
+
+// ErrField holds an error logged as structured fields,
+// use Err to create one.
+type ErrField struct {
+	err error
+}
+
+// Err returns the error as structured fields to pass along with
+// the key/value arguments of a log method. It adds the error message
+// as `error`, its type as `error_type`, the messages of the wrapped
+// errors as `causes` and the stack trace as `stack` if available.
+// For example:
+//     l.Error("request failed", log.Err(err), "path", path)
+func Err(err error) ErrField {
+	return ErrField{err: err}
+}
+
+func (e ErrField) fields() []interface{} {
+	if e.err == nil {
+		return nil
+	}
+
+	fields := []interface{}{
+		"error", e.err.Error(),
+		"error_type", fmt.Sprintf("%T", e.err),
+	}
+
+	var causes []string
+	var stack pkgerrors.StackTrace
+	for err := e.err; err != nil; err = errors.Unwrap(err) {
+		if err != e.err {
+			causes = append(causes, err.Error())
+		}
+		// the deepest stack trace is closest to the origin
+		if st, ok := err.(interface{ StackTrace() pkgerrors.StackTrace }); ok {
+			stack = st.StackTrace()
+		}
+	}
+
+	if len(causes) > 0 {
+		fields = append(fields, "causes", causes)
+	}
+	if stack != nil {
+		fields = append(fields, "stack", fmt.Sprintf("%+v", stack))
+	}
+	return fields
+}
+
+// OmitemptyField holds a key/value pair omitted if the value
+// is empty, use Omitempty to create one.
+type OmitemptyField struct {
+	key   string
+	value interface{}
+}
+
+// Omitempty returns the key/value pair to pass along with the key/value
+// arguments of a log method, the pair is left out if the value is nil,
+// an empty string, slice or map, or a nil pointer. Zero numbers and
+// false are logged.
+// For example:
+//     l.Info("request served", "path", path, log.Omitempty("user_id", userID))
+func Omitempty(key string, value interface{}) OmitemptyField {
+	return OmitemptyField{key: key, value: value}
+}
+
+func (f OmitemptyField) fields() []interface{} {
+	if isEmpty(f.value) {
+		return nil
+	}
+	return []interface{}{f.key, f.value}
+}
+
+func isEmpty(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+// ValueEncoder converts a field value before it is formatted
+type ValueEncoder func(v interface{}) interface{}
+
+// WithValueEncoder converts the value of every field with the encoder
+// before it is formatted, so values render the same regardless of the
+// formatter, it applies to logrus and zap loggers.
+// For example, to log times in RFC3339 and errors with their stack:
+//     l := log.NewLogrus(log.WithValueEncoder(func(v interface{}) interface{} {
+//         switch v := v.(type) {
+//         case time.Time:
+//             return v.Format(time.RFC3339)
+//         case error:
+//             return fmt.Sprintf("%+v", v)
+//         }
+//         return v
+//     }))
+func WithValueEncoder(encoder ValueEncoder) Option {
+	return func(logger interface{}) {
+		switch l := logger.(type) {
+		case *Logrus:
+			l.encoder = encoder
+		case *Zap:
+			l.encoder = encoder
+		}
+	}
+}
+
+// encodeValues returns a copy of the key/value arguments
+// with the values converted by the encoder
+func encodeValues(args []interface{}, encoder ValueEncoder) []interface{} {
+	encoded := make([]interface{}, len(args))
+	copy(encoded, args)
+	for i := 1; i < len(encoded); i += 2 {
+		encoded[i] = encoder(encoded[i])
+	}
+	return encoded
+}
+
+// expandFields replaces the arguments expanding into
+// multiple key/value pairs with the pairs
+func expandFields(args []interface{}) []interface{} {
+	expand := false
+	for _, arg := range args {
+		if _, ok := arg.(fielder); ok {
+			expand = true
+			break
+		}
+	}
+	if !expand {
+		return args
+	}
+
+	expanded := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if f, ok := arg.(fielder); ok {
+			expanded = append(expanded, f.fields()...)
+			continue
+		}
+		expanded = append(expanded, arg)
+	}
+	return expanded
+}
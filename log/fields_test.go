@@ -0,0 +1,155 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErr(t *testing.T) {
+	logEntry := func(t *testing.T, args ...interface{}) map[string]interface{} {
+		t.Helper()
+
+		var b bytes.Buffer
+		logger := log.NewLogrus(log.LogrusWithWriter(&b), log.LogrusWithFormatter(&logrus.JSONFormatter{
+			DisableTimestamp: true,
+		}))
+		logger.Error("request failed", args...)
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &entry))
+		return entry
+	}
+
+	t.Run("should add error message and type as fields", func(t *testing.T) {
+		entry := logEntry(t, log.Err(errors.New("connection refused")), "path", "/ping")
+
+		assert.Equal(t, map[string]interface{}{
+			"level":      "error",
+			"msg":        "request failed",
+			"error":      "connection refused",
+			"error_type": "*errors.errorString",
+			"path":       "/ping",
+		}, entry)
+	})
+
+	t.Run("should add unwrapped causes", func(t *testing.T) {
+		root := errors.New("connection refused")
+		err := fmt.Errorf("fetching user: %w", fmt.Errorf("querying db: %w", root))
+
+		entry := logEntry(t, log.Err(err))
+
+		assert.Equal(t, "fetching user: querying db: connection refused", entry["error"])
+		assert.Equal(t, []interface{}{
+			"querying db: connection refused",
+			"connection refused",
+		}, entry["causes"])
+	})
+
+	t.Run("should add stack trace if available", func(t *testing.T) {
+		err := fmt.Errorf("fetching user: %w", pkgerrors.New("connection refused"))
+
+		entry := logEntry(t, log.Err(err))
+
+		assert.Contains(t, entry["stack"], "TestErr")
+	})
+
+	t.Run("should add no fields for nil error", func(t *testing.T) {
+		entry := logEntry(t, log.Err(nil))
+
+		assert.Equal(t, map[string]interface{}{
+			"level": "error",
+			"msg":   "request failed",
+		}, entry)
+	})
+}
+
+func TestOmitempty(t *testing.T) {
+	logLine := func(args ...interface{}) string {
+		var b bytes.Buffer
+		logger := log.NewLogrus(log.LogrusWithWriter(&b), log.LogrusWithFormatter(&logrus.TextFormatter{
+			DisableTimestamp: true,
+		}))
+		logger.Info("request served", args...)
+		return b.String()
+	}
+
+	t.Run("should omit empty values", func(t *testing.T) {
+		var user *struct{ ID string }
+		line := logLine(
+			"path", "/ping",
+			log.Omitempty("user_id", ""),
+			log.Omitempty("user", user),
+			log.Omitempty("tags", []string{}),
+			log.Omitempty("labels", map[string]string(nil)),
+			log.Omitempty("error", nil),
+		)
+
+		assert.Equal(t, "level=info msg=\"request served\" path=/ping\n", line)
+	})
+
+	t.Run("should keep non empty values", func(t *testing.T) {
+		line := logLine(
+			log.Omitempty("user_id", "42"),
+			log.Omitempty("tags", []string{"a"}),
+			log.Omitempty("retries", 0),
+			log.Omitempty("cached", false),
+		)
+
+		assert.Equal(t, "level=info msg=\"request served\" cached=false retries=0 tags=\"[a]\" user_id=42\n", line)
+	})
+}
+
+func TestWithValueEncoder(t *testing.T) {
+	encoder := func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case time.Time:
+			return v.Format(time.RFC3339)
+		case error:
+			return fmt.Sprintf("%+v", v)
+		}
+		return v
+	}
+
+	t.Run("should normalize time values", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := log.NewLogrus(
+			log.LogrusWithWriter(&b),
+			log.LogrusWithFormatter(&logrus.JSONFormatter{DisableTimestamp: true}),
+			log.WithValueEncoder(encoder),
+		)
+
+		at := time.Date(2021, 10, 1, 12, 0, 0, 123, time.FixedZone("IST", 19800))
+		logger.WithFields(map[string]interface{}{"created_at": at}).Info("user created", "updated_at", at)
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &entry))
+		assert.Equal(t, "2021-10-01T12:00:00+05:30", entry["created_at"])
+		assert.Equal(t, "2021-10-01T12:00:00+05:30", entry["updated_at"])
+	})
+
+	t.Run("should expand error values", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := log.NewLogrus(
+			log.LogrusWithWriter(&b),
+			log.LogrusWithFormatter(&logrus.JSONFormatter{DisableTimestamp: true}),
+			log.WithValueEncoder(encoder),
+		)
+
+		err := pkgerrors.New("connection refused")
+		logger.Error("request failed", "err", err)
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &entry))
+		assert.Equal(t, fmt.Sprintf("%+v", err), entry["err"])
+		assert.Contains(t, entry["err"], "fields_test.go")
+	})
+}
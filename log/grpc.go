@@ -0,0 +1,70 @@
+package log
+
+import (
+	"context"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor logs every unary RPC with the `method`, `peer`,
+// `code` and `duration` written as fields. The handlers can retrieve a
+// logger with the method and peer fields with FromContext.
+// RPCs failing with a server error code, e.g. Internal or Unavailable,
+// are logged at error level and the rest at info level.
+// For example:
+//   grpc.NewServer(grpc.UnaryInterceptor(log.UnaryServerInterceptor(l)))
+func UnaryServerInterceptor(l Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		rl := rpcLogger(ctx, l, info.FullMethod)
+
+		resp, err := handler(NewContext(ctx, rl), req)
+		logRPC(rl, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs every streaming RPC the same
+// way as UnaryServerInterceptor, once the stream is done.
+func StreamServerInterceptor(l Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		rl := rpcLogger(ss.Context(), l, info.FullMethod)
+
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = NewContext(ss.Context(), rl)
+
+		err := handler(srv, wrapped)
+		logRPC(rl, err, time.Since(start))
+		return err
+	}
+}
+
+func rpcLogger(ctx context.Context, l Logger, method string) Logger {
+	fields := map[string]interface{}{"method": method}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields["peer"] = p.Addr.String()
+	}
+	return l.WithFields(fields)
+}
+
+func logRPC(l Logger, err error, duration time.Duration) {
+	code := status.Code(err)
+	fields := []interface{}{"code", code.String(), "duration", duration}
+	if err != nil {
+		fields = append(fields, "error", err.Error())
+	}
+
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented,
+		codes.Internal, codes.Unavailable, codes.DataLoss:
+		l.Error("grpc request", fields...)
+	default:
+		l.Info("grpc request", fields...)
+	}
+}
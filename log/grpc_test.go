@@ -0,0 +1,141 @@
+package log_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/odpf/salt/log"
+)
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	log.FromContext(ctx).Info("checking health", "service", req.Service)
+	if req.Service == "broken" {
+		return nil, status.Error(codes.Internal, "database unreachable")
+	}
+	if req.Service == "unknown" {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	log.FromContext(stream.Context()).Info("watching health", "service", req.Service)
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+}
+
+func TestServerInterceptors(t *testing.T) {
+	var b syncBuffer
+	logger := log.NewLogrus(log.LogrusWithWriter(&b), log.LogrusWithFormatter(&logrus.JSONFormatter{}))
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(log.UnaryServerInterceptor(logger)),
+		grpc.StreamInterceptor(log.StreamServerInterceptor(logger)),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, &healthServer{})
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	// entries returns the entries logged since the last call
+	var read int
+	entries := func(t *testing.T) []map[string]interface{} {
+		t.Helper()
+
+		out := b.String()
+		lines := strings.Split(strings.TrimSpace(out[read:]), "\n")
+		read = len(out)
+
+		var result []map[string]interface{}
+		for _, line := range lines {
+			var entry map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+			result = append(result, entry)
+		}
+		return result
+	}
+
+	t.Run("should log unary request with ok code", func(t *testing.T) {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "app"})
+		assert.NoError(t, err)
+
+		logs := entries(t)
+		assert.Len(t, logs, 2)
+
+		assert.Equal(t, "checking health", logs[0]["msg"])
+		assert.Equal(t, "/grpc.health.v1.Health/Check", logs[0]["method"])
+		assert.Equal(t, "bufconn", logs[0]["peer"])
+
+		assert.Equal(t, "grpc request", logs[1]["msg"])
+		assert.Equal(t, "info", logs[1]["level"])
+		assert.Equal(t, "/grpc.health.v1.Health/Check", logs[1]["method"])
+		assert.Equal(t, "bufconn", logs[1]["peer"])
+		assert.Equal(t, "OK", logs[1]["code"])
+		assert.Contains(t, logs[1], "duration")
+		assert.NotContains(t, logs[1], "error")
+	})
+
+	t.Run("should log unary request with error code", func(t *testing.T) {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "broken"})
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		logs := entries(t)
+		assert.Len(t, logs, 2)
+		assert.Equal(t, "error", logs[1]["level"])
+		assert.Equal(t, "Internal", logs[1]["code"])
+		assert.Equal(t, "rpc error: code = Internal desc = database unreachable", logs[1]["error"])
+	})
+
+	t.Run("should log client error codes at info level", func(t *testing.T) {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		logs := entries(t)
+		assert.Len(t, logs, 2)
+		assert.Equal(t, "info", logs[1]["level"])
+		assert.Equal(t, "NotFound", logs[1]["code"])
+	})
+
+	t.Run("should log stream request", func(t *testing.T) {
+		stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "app"})
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.True(t, errors.Is(err, io.EOF))
+
+		logs := entries(t)
+		assert.Len(t, logs, 2)
+
+		assert.Equal(t, "watching health", logs[0]["msg"])
+		assert.Equal(t, "/grpc.health.v1.Health/Watch", logs[0]["method"])
+
+		assert.Equal(t, "grpc request", logs[1]["msg"])
+		assert.Equal(t, "/grpc.health.v1.Health/Watch", logs[1]["method"])
+		assert.Equal(t, "OK", logs[1]["code"])
+	})
+}
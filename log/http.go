@@ -0,0 +1,71 @@
+package log
+
+import (
+	"net/http"
+	"time"
+)
+
+// responseRecorder records the status and size of the response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Flush sends the buffered response to the client
+// if supported by the wrapped writer, e.g. for streaming
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// HTTPMiddleware logs every request handled by next with the `method`,
+// `path`, `status`, `duration` and `bytes` written as fields.
+// Requests failing with a 5xx status are logged at error level and
+// the rest at info level.
+// For example:
+//   http.ListenAndServe(":8080", log.HTTPMiddleware(l)(mux))
+func HTTPMiddleware(l Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			fields := []interface{}{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"duration", time.Since(start),
+				"bytes", rec.bytes,
+			}
+			if status >= http.StatusInternalServerError {
+				l.Error("http request", fields...)
+			} else {
+				l.Info("http request", fields...)
+			}
+		})
+	}
+}
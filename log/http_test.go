@@ -0,0 +1,80 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/odpf/salt/log"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	serve := func(t *testing.T, handler http.HandlerFunc, req *http.Request) (*httptest.ResponseRecorder, map[string]interface{}) {
+		t.Helper()
+
+		var b bytes.Buffer
+		logger := log.NewLogrus(log.LogrusWithWriter(&b), log.LogrusWithFormatter(&logrus.JSONFormatter{}))
+
+		rec := httptest.NewRecorder()
+		log.HTTPMiddleware(logger)(handler).ServeHTTP(rec, req)
+
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b.Bytes(), &entry))
+		return rec, entry
+	}
+
+	t.Run("should log request fields", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Millisecond)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("created"))
+		}
+
+		rec, entry := serve(t, handler, httptest.NewRequest(http.MethodPost, "/users?name=foo", nil))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "created", rec.Body.String())
+
+		assert.Equal(t, "info", entry["level"])
+		assert.Equal(t, "http request", entry["msg"])
+		assert.Equal(t, "POST", entry["method"])
+		assert.Equal(t, "/users", entry["path"])
+		assert.Equal(t, float64(http.StatusCreated), entry["status"])
+		assert.Equal(t, float64(len("created")), entry["bytes"])
+		assert.GreaterOrEqual(t, entry["duration"], float64(time.Millisecond))
+	})
+
+	t.Run("should log ok status if not written", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {}
+
+		_, entry := serve(t, handler, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		assert.Equal(t, float64(http.StatusOK), entry["status"])
+		assert.Equal(t, float64(0), entry["bytes"])
+	})
+
+	t.Run("should log server errors at error level", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+
+		rec, entry := serve(t, handler, httptest.NewRequest(http.MethodGet, "/ping", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "error", entry["level"])
+		assert.Equal(t, float64(http.StatusInternalServerError), entry["status"])
+	})
+
+	t.Run("should flush the wrapped writer", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("data"))
+			w.(http.Flusher).Flush()
+		}
+
+		rec, _ := serve(t, handler, httptest.NewRequest(http.MethodGet, "/events", nil))
+		assert.True(t, rec.Flushed)
+	})
+}
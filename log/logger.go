@@ -43,6 +43,19 @@ type Logger interface {
 	// Level returns priority level for which this logger will filter logs
 	Level() string
 
+	// Enabled returns true if messages at the level are logged, it can
+	// guard the computation of expensive fields e.g. in hot paths
+	Enabled(level string) bool
+
 	// Writer used to print logs
 	Writer() io.Writer
+
+	// WithFields returns a logger adding the fields to every message
+	WithFields(fields map[string]interface{}) Logger
+
+	// Close writes the buffered logs and flushes and closes the writers
+	// of the logger, it should be called before the process exits.
+	// The loggers returned by WithFields do not close the writers
+	// they share with their parent.
+	Close() error
 }
@@ -1,53 +1,275 @@
 package log
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 type Logrus struct {
-	log *logrus.Logger
+	log     *logrus.Logger
+	sampler *sampler
+	caller  bool
+	fields  map[string]interface{}
+	order   []string
+	router  *routerHook
+	encoder ValueEncoder
+
+	otlpEndpoint string
+	otlp         *otlpExporter
+
+	// the writer set by the options, closed by Close
+	out io.Writer
+
+	// set on the loggers of WithFields which share the
+	// writers and exporter of the logger closing them
+	derived bool
+
+	asyncBufSize    int
+	asyncDropOnFull bool
+	async           *asyncWriter
 }
 
+// fieldsPool reuses the field maps of the messages, logrus
+// copies the fields into the entry so the map is not retained
+var fieldsPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[string]interface{}, 8)
+	},
+}
+
+// maxPooledFields is the size over which field maps are not pooled
+// to not keep the memory of rare messages with many fields
+const maxPooledFields = 64
+
 func (l Logrus) getFields(args ...interface{}) map[string]interface{} {
-	fieldMap := map[string]interface{}{}
+	fieldMap := make(map[string]interface{}, len(l.fields)+len(args)/2)
+	l.setFields(fieldMap, args)
+	return fieldMap
+}
+
+// setFields sets the fields of the logger and the key/value
+// arguments of a message in fieldMap
+func (l Logrus) setFields(fieldMap map[string]interface{}, args []interface{}) {
+	args = expandFields(args)
+	for k, v := range l.fields {
+		fieldMap[k] = v
+	}
 	if len(args) > 1 && len(args)%2 == 0 {
 		for i := 1; i < len(args); i += 2 {
 			fieldMap[args[i-1].(string)] = args[i]
 		}
 	}
-	return fieldMap
+	if l.encoder != nil {
+		for k, v := range fieldMap {
+			fieldMap[k] = l.encoder(v)
+		}
+	}
+	// a caller field set by the user is kept
+	if _, ok := fieldMap["caller"]; l.caller && !ok {
+		fieldMap["caller"] = caller()
+	}
+}
+
+// logAt logs the message at the level unless the level is disabled
+// or the message is sampled out, without building the fields then
+func (l *Logrus) logAt(level logrus.Level, msg string, args []interface{}) {
+	if !l.log.IsLevelEnabled(level) || !l.sampled(level, msg) {
+		return
+	}
+
+	fieldMap := fieldsPool.Get().(map[string]interface{})
+	l.setFields(fieldMap, args)
+	entry := l.log.WithFields(fieldMap)
+	if len(fieldMap) <= maxPooledFields {
+		for k := range fieldMap {
+			delete(fieldMap, k)
+		}
+		fieldsPool.Put(fieldMap)
+	}
+	entry.Log(level, msg)
 }
 
 func (l *Logrus) Info(msg string, args ...interface{}) {
-	l.log.WithFields(l.getFields(args...)).Info(msg)
+	l.logAt(logrus.InfoLevel, msg, args)
 }
 
 func (l *Logrus) Debug(msg string, args ...interface{}) {
-	l.log.WithFields(l.getFields(args...)).Debug(msg)
+	l.logAt(logrus.DebugLevel, msg, args)
 }
 
 func (l *Logrus) Warn(msg string, args ...interface{}) {
-	l.log.WithFields(l.getFields(args...)).Warn(msg)
+	l.logAt(logrus.WarnLevel, msg, args)
 }
 
 func (l *Logrus) Error(msg string, args ...interface{}) {
-	l.log.WithFields(l.getFields(args...)).Error(msg)
+	l.logAt(logrus.ErrorLevel, msg, args)
 }
 
 func (l *Logrus) Fatal(msg string, args ...interface{}) {
 	l.log.WithFields(l.getFields(args...)).Fatal(msg)
 }
 
+// RegisterExitHandler adds a handler called before the process
+// exits on Fatal, e.g. to flush buffers or close connections.
+// Handlers run in the order they are registered, a panic in a
+// handler is recovered. Handlers are shared by all logrus loggers
+// and delay the exit, so they must be fast.
+func (l *Logrus) RegisterExitHandler(handler func()) {
+	logrus.RegisterExitHandler(handler)
+}
+
+func (l *Logrus) sampled(level logrus.Level, msg string) bool {
+	if l.sampler == nil || !l.log.IsLevelEnabled(level) {
+		return true
+	}
+	return l.sampler.allow(level.String(), msg)
+}
+
 func (l *Logrus) Level() string {
 	return l.log.Level.String()
 }
 
+// Enabled returns true if messages at the level are logged,
+// it returns false for an invalid level.
+func (l *Logrus) Enabled(level string) bool {
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return false
+	}
+	return l.log.IsLevelEnabled(logLevel)
+}
+
 func (l *Logrus) Writer() io.Writer {
 	return l.log.Writer()
 }
 
+// WriterLevel returns a writer logging each line written to it
+// at the level, e.g. to use as the error log of http.Server.
+// The writer must be closed when no longer used to stop
+// the goroutine reading from it.
+func (l *Logrus) WriterLevel(level string) io.WriteCloser {
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		panic(err)
+	}
+	return l.log.WriterLevel(logLevel)
+}
+
+// WithFields returns a logger sharing the configuration of l
+// which adds the fields to every message, key/value arguments
+// of a message override the fields with the same key.
+// Its Close is a no-op, the writers are closed by the
+// logger returned by NewLogrus.
+func (l *Logrus) WithFields(fields map[string]interface{}) Logger {
+	child := *l
+	child.derived = true
+	child.fields = make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		child.fields[k] = v
+	}
+	for k, v := range fields {
+		child.fields[k] = v
+	}
+	return &child
+}
+
+// Flush blocks until the logs buffered by LogrusWithAsyncWriter
+// are written and the ones buffered by LogrusWithOTLPExport are
+// exported, it is a no-op for synchronous loggers.
+func (l *Logrus) Flush() {
+	if l.async != nil {
+		l.async.Flush()
+	}
+	if l.otlp != nil {
+		if err := l.otlp.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export logs: %v\n", err)
+		}
+	}
+}
+
+// Close writes the logs buffered by LogrusWithAsyncWriter, exports the
+// ones buffered by LogrusWithOTLPExport and stops the background writer
+// and exporter. The writers of the logger, including the routes, are then
+// flushed if they have a Flush method, e.g. bufio.Writer, and closed if
+// they are io.Closer, except stdout and stderr. Logs made after Close,
+// e.g. by goroutines still running, are written and exported
+// synchronously, and fail on the writers it closed. It should be
+// called before the process exits, e.g. deferred in main, and is
+// called before exiting on Fatal. It is a no-op on the loggers
+// returned by WithFields.
+func (l *Logrus) Close() error {
+	if l.derived {
+		return nil
+	}
+	if l.async != nil {
+		l.async.Close()
+	}
+
+	var err error
+	if l.otlp != nil {
+		err = l.otlp.Close()
+	}
+	for _, w := range l.writers() {
+		if cerr := closeWriter(w); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// writers returns the writers the logs are written to, once each
+func (l *Logrus) writers() []io.Writer {
+	candidates := []io.Writer{l.out}
+	if l.router != nil {
+		candidates = []io.Writer{l.router.fallback}
+		for _, w := range l.router.routes {
+			candidates = append(candidates, w)
+		}
+	}
+
+	var writers []io.Writer
+	for _, w := range candidates {
+		if w == nil {
+			continue
+		}
+		dup := false
+		for _, seen := range writers {
+			if reflect.TypeOf(w).Comparable() && reflect.TypeOf(seen).Comparable() && w == seen {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			writers = append(writers, w)
+		}
+	}
+	return writers
+}
+
+// closeWriter flushes and closes the writer, stdout and
+// stderr are only flushed as they are shared by the process
+func closeWriter(w io.Writer) error {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 func (l *Logrus) Entry(args ...interface{}) *logrus.Entry {
 	return l.log.WithFields(l.getFields(args...))
 }
@@ -62,6 +284,19 @@ func LogrusWithLevel(level string) Option {
 	}
 }
 
+// LogrusWithLevelFromEnv sets the level from the env variable, e.g.
+// `LOG_LEVEL`, falling back to the default level if the variable is
+// unset or not a valid level. It panics if the default is invalid.
+func LogrusWithLevelFromEnv(envName, defaultLevel string) Option {
+	return func(logger interface{}) {
+		if logLevel, err := logrus.ParseLevel(os.Getenv(envName)); err == nil {
+			logger.(*Logrus).log.SetLevel(logLevel)
+			return
+		}
+		LogrusWithLevel(defaultLevel)(logger)
+	}
+}
+
 func LogrusWithWriter(writer io.Writer) Option {
 	return func(logger interface{}) {
 		logger.(*Logrus).log.SetOutput(writer)
@@ -82,6 +317,94 @@ func LogrusWithFormatter(f logrus.Formatter) Option {
 	}
 }
 
+// LogrusWithConsoleFormat formats logs for humans reading them in
+// the terminal using ConsoleFormatter, with colors if the logs are
+// written to a terminal.
+func LogrusWithConsoleFormat() Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).log.SetFormatter(&ConsoleFormatter{})
+	}
+}
+
+// LogrusWithSampling caps repeated logs with the same level and
+// message to the first logs in each tick and every thereafter-th
+// log after that, a thereafter of 0 drops the rest. Fatal logs
+// are never sampled.
+// For example, to log at most 10 identical messages per second:
+//   l := log.NewLogrus(log.LogrusWithSampling(time.Second, 10, 0))
+func LogrusWithSampling(tick time.Duration, first int, thereafter int) Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).sampler = newSampler(tick, first, thereafter)
+	}
+}
+
+// LogrusWithCaller adds the file and line logs are made from as the
+// `caller` field, e.g. `caller=server/server.go:42`, unless the
+// message or the logger already has a caller field. logrus's own
+// caller reporting is not used as it reports this package as the caller.
+func LogrusWithCaller(enabled bool) Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).caller = enabled
+	}
+}
+
+// LogrusWithFieldOrder writes the fields in keys first, in the given order,
+// followed by the other fields in alphabetical order, so the columns of the
+// logs line up regardless of the order the fields are passed in.
+// It applies to the logrus.TextFormatter, JSON logs are already sorted.
+// For example:
+//   l := log.NewLogrus(log.LogrusWithFieldOrder("request_id", "method", "path"))
+func LogrusWithFieldOrder(keys ...string) Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).order = keys
+	}
+}
+
+// LogrusWithExitFunc sets the function called to exit the process
+// on Fatal after the exit handlers, os.Exit by default.
+func LogrusWithExitFunc(exit func(code int)) Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).log.ExitFunc = exit
+	}
+}
+
+// LogrusWithAsyncWriter writes logs to the writer in the background
+// so slow writers, e.g. over the network, do not block the callers.
+// Up to bufSize logs are buffered, once full new logs are dropped if
+// dropOnFull is set, or the callers block until there is space.
+// Buffered logs are written on Flush, Close and before exiting on Fatal.
+func LogrusWithAsyncWriter(bufSize int, dropOnFull bool) Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).asyncBufSize = bufSize
+		logger.(*Logrus).asyncDropOnFull = dropOnFull
+	}
+}
+
+type metricsHook struct {
+	counter func(level string)
+}
+
+func (h metricsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h metricsHook) Fire(entry *logrus.Entry) error {
+	h.counter(entry.Level.String())
+	return nil
+}
+
+// LogrusWithMetrics calls counter with the level of every emitted
+// log, logs filtered out by the level are not counted.
+// For example, to count logs with a prometheus.CounterVec:
+//   l := log.NewLogrus(log.LogrusWithMetrics(func(level string) {
+//       logsTotal.WithLabelValues(level).Inc()
+//   }))
+func LogrusWithMetrics(counter func(level string)) Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).log.AddHook(metricsHook{counter: counter})
+	}
+}
+
 // NewLogrus returns a logrus logger instance with info level as default log level
 func NewLogrus(opts ...Option) *Logrus {
 	logger := &Logrus{
@@ -91,5 +414,38 @@ func NewLogrus(opts ...Option) *Logrus {
 	for _, opt := range opts {
 		opt(logger)
 	}
+
+	// the formatter may be set by any of the options
+	if f, ok := logger.log.Formatter.(*logrus.TextFormatter); ok && len(logger.order) > 0 {
+		f.SortingFunc = fieldOrder(f, logger.order)
+	}
+
+	// the routes replace the writer set by any of the options
+	if logger.router != nil {
+		logger.log.AddHook(logger.router)
+		logger.log.SetOutput(ioutil.Discard)
+	}
+
+	if logger.otlpEndpoint != "" {
+		logger.otlp = newOTLPExporter(logger.otlpEndpoint)
+		logger.log.AddHook(logger.otlp)
+	}
+
+	// wrap the writer once all the options are applied
+	logger.out = logger.log.Out
+	if logger.asyncBufSize > 0 {
+		logger.async = newAsyncWriter(logger.log.Out, logger.asyncBufSize, logger.asyncDropOnFull)
+		logger.log.SetOutput(logger.async)
+	}
+
+	// write the buffered logs before exiting on Fatal
+	exit := logger.log.ExitFunc
+	if exit == nil {
+		exit = os.Exit
+	}
+	logger.log.ExitFunc = func(code int) {
+		_ = logger.Close()
+		exit(code)
+	}
 	return logger
 }
@@ -0,0 +1,30 @@
+package log_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/odpf/salt/log"
+)
+
+func BenchmarkLogrusFields(b *testing.B) {
+	logger := log.NewLogrus(log.LogrusWithWriter(ioutil.Discard), log.LogrusWithFormatter(&logrus.TextFormatter{
+		DisableTimestamp: true,
+	}))
+
+	b.Run("info with two fields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Info("processed request", "method", "GET", "status", 200)
+		}
+	})
+
+	b.Run("debug filtered by level", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Debug("processed request", "method", "GET", "status", 200)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"log/syslog"
+
+	lsyslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+// LogrusWithSyslog sends the logs to the syslog daemon at addr
+// in addition to the writer, an empty network connects to the
+// local daemon. The severity of priority is set from the log level.
+// For example:
+//   l := log.NewLogrus(log.LogrusWithSyslog("udp", "localhost:514", "app", syslog.LOG_LOCAL0))
+func LogrusWithSyslog(network, addr, tag string, priority syslog.Priority) Option {
+	return func(logger interface{}) {
+		hook, err := lsyslog.NewSyslogHook(network, addr, priority, tag)
+		if err != nil {
+			panic(err)
+		}
+		logger.(*Logrus).log.AddHook(hook)
+	}
+}
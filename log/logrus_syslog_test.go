@@ -0,0 +1,58 @@
+//go:build !windows
+// +build !windows
+
+package log_test
+
+import (
+	"io/ioutil"
+	"log/syslog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogrusWithSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	read := func() string {
+		buf := make([]byte, 1024)
+		assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return ""
+		}
+		return string(buf[:n])
+	}
+
+	logger := log.NewLogrus(
+		log.LogrusWithLevel("warn"),
+		log.LogrusWithWriter(ioutil.Discard),
+		log.LogrusWithSyslog("udp", conn.LocalAddr().String(), "salt", syslog.LOG_LOCAL0),
+	)
+
+	t.Run("should map levels to syslog severity", func(t *testing.T) {
+		logger.Warn("disk almost full")
+		msg := read()
+		// LOG_LOCAL0 (16<<3) | LOG_WARNING (4)
+		assert.True(t, strings.HasPrefix(msg, "<132>"), msg)
+		assert.Contains(t, msg, "salt")
+		assert.Contains(t, msg, "disk almost full")
+
+		logger.Error("disk full")
+		msg = read()
+		// LOG_LOCAL0 (16<<3) | LOG_ERR (3)
+		assert.True(t, strings.HasPrefix(msg, "<131>"), msg)
+		assert.Contains(t, msg, "disk full")
+	})
+
+	t.Run("should not send logs below the level", func(t *testing.T) {
+		logger.Info("started")
+		assert.Empty(t, read())
+	})
+}
@@ -4,7 +4,16 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
+	stdlog "log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -75,3 +84,547 @@ func TestLogrus(t *testing.T) {
 		assert.Equal(t, "level=error msg=\"request failed\"\n", b.String())
 	})
 }
+
+func TestLogrusFields(t *testing.T) {
+	newLogger := func(b *bytes.Buffer) *log.Logrus {
+		return log.NewLogrus(log.LogrusWithWriter(b), log.LogrusWithFormatter(&logrus.TextFormatter{
+			DisableTimestamp: true,
+		}))
+	}
+
+	t.Run("should log the same fields as the entry", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b).WithFields(map[string]interface{}{"service": "api"}).(*log.Logrus)
+		args := []interface{}{"method", "GET", log.Omitempty("user", ""), "status", 200}
+
+		logger.Info("processed", args...)
+
+		entry := logger.Entry(args...)
+		assert.Equal(t, logrus.Fields{"service": "api", "method": "GET", "status": 200}, entry.Data)
+		assert.Equal(t, "level=info msg=processed method=GET service=api status=200\n", b.String())
+	})
+
+	t.Run("should not leak fields between messages", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b)
+
+		for i := 0; i < 100; i++ {
+			logger.Info("first", "request_id", i)
+		}
+		b.Reset()
+		logger.Info("second")
+		logger.Warn("third", "odd")
+
+		assert.Equal(t, "level=info msg=second\nlevel=warning msg=third\n", b.String())
+	})
+}
+
+func TestLogrusWithSampling(t *testing.T) {
+	newLogger := func(b *bytes.Buffer, tick time.Duration, first, thereafter int) *log.Logrus {
+		return log.NewLogrus(
+			log.LogrusWithLevel("info"),
+			log.LogrusWithWriter(b),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			log.LogrusWithSampling(tick, first, thereafter),
+		)
+	}
+
+	t.Run("should log first messages and every thereafter-th message in a tick", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b, time.Minute, 3, 10)
+
+		for i := 0; i < 100; i++ {
+			logger.Warn("disk almost full", "attempt", i)
+		}
+
+		// 3 first and 1 in 10 of the remaining 97
+		assert.Equal(t, 12, strings.Count(b.String(), "\n"))
+		assert.Contains(t, b.String(), "attempt=2\n")
+		assert.NotContains(t, b.String(), "attempt=3\n")
+		assert.Contains(t, b.String(), "attempt=12\n")
+	})
+
+	t.Run("should sample messages and levels separately", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b, time.Minute, 1, 0)
+
+		for i := 0; i < 10; i++ {
+			logger.Warn("disk almost full")
+			logger.Warn("disk full")
+			logger.Error("disk full")
+		}
+
+		assert.Equal(t, "level=warning msg=\"disk almost full\"\n"+
+			"level=warning msg=\"disk full\"\n"+
+			"level=error msg=\"disk full\"\n", b.String())
+	})
+
+	t.Run("should reset counts after tick", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b, 20*time.Millisecond, 1, 0)
+
+		logger.Info("polling")
+		logger.Info("polling")
+		time.Sleep(30 * time.Millisecond)
+		logger.Info("polling")
+
+		assert.Equal(t, 2, strings.Count(b.String(), "\n"))
+	})
+}
+
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestLogrusWriterLevel(t *testing.T) {
+	t.Run("should log lines written at the level", func(t *testing.T) {
+		var b syncBuffer
+		logger := log.NewLogrus(log.LogrusWithWriter(&b), log.LogrusWithFormatter(&logrus.TextFormatter{
+			DisableTimestamp: true,
+		}))
+
+		w := logger.WriterLevel("error")
+		defer w.Close()
+		stdlog.New(w, "", 0).Print("http: TLS handshake error")
+
+		assert.Eventually(t, func() bool {
+			return b.String() == "level=error msg=\"http: TLS handshake error\"\n"
+		}, time.Second, 10*time.Millisecond, b.String())
+	})
+
+	t.Run("should panic for invalid level", func(t *testing.T) {
+		logger := log.NewLogrus()
+
+		assert.Panics(t, func() {
+			logger.WriterLevel("verbose")
+		})
+	})
+}
+
+func TestLogrusWithMetrics(t *testing.T) {
+	t.Run("should count emitted logs by level", func(t *testing.T) {
+		counts := map[string]int{}
+		logger := log.NewLogrus(
+			log.LogrusWithLevel("info"),
+			log.LogrusWithWriter(ioutil.Discard),
+			log.LogrusWithMetrics(func(level string) {
+				counts[level]++
+			}),
+		)
+
+		logger.Debug("suppressed")
+		logger.Info("started")
+		logger.Info("ready")
+		logger.Warn("slow request")
+		logger.Error("request failed")
+
+		assert.Equal(t, map[string]int{
+			"info":    2,
+			"warning": 1,
+			"error":   1,
+		}, counts)
+	})
+}
+
+func TestLogrusRegisterExitHandler(t *testing.T) {
+	t.Run("should run exit handlers in order before exiting on fatal", func(t *testing.T) {
+		var calls []string
+		var b bytes.Buffer
+		logger := log.NewLogrus(
+			log.LogrusWithWriter(&b),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			log.LogrusWithExitFunc(func(code int) {
+				calls = append(calls, fmt.Sprintf("exit %d", code))
+			}),
+		)
+		logger.RegisterExitHandler(func() { calls = append(calls, "flush") })
+		logger.RegisterExitHandler(func() { calls = append(calls, "close") })
+
+		logger.Fatal("shutting down")
+
+		assert.Equal(t, "level=fatal msg=\"shutting down\"\n", b.String())
+		assert.Equal(t, []string{"flush", "close", "exit 1"}, calls)
+	})
+}
+
+// slowWriter blocks writes until released
+type slowWriter struct {
+	syncBuffer
+	writing chan struct{}
+	release chan struct{}
+}
+
+func newSlowWriter() *slowWriter {
+	return &slowWriter{
+		writing: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	w.writing <- struct{}{}
+	<-w.release
+	return w.syncBuffer.Write(p)
+}
+
+func TestLogrusWithAsyncWriter(t *testing.T) {
+	newLogger := func(w io.Writer, bufSize int, dropOnFull bool) *log.Logrus {
+		return log.NewLogrus(
+			log.LogrusWithWriter(w),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			log.LogrusWithAsyncWriter(bufSize, dropOnFull),
+		)
+	}
+
+	t.Run("should eventually write logs", func(t *testing.T) {
+		var b syncBuffer
+		logger := newLogger(&b, 10, false)
+		defer logger.Close()
+
+		logger.Info("started")
+		logger.Info("ready")
+
+		assert.Eventually(t, func() bool {
+			return b.String() == "level=info msg=started\nlevel=info msg=ready\n"
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("should not block on slow writer", func(t *testing.T) {
+		w := newSlowWriter()
+		logger := newLogger(w, 10, false)
+
+		logger.Info("started")
+		logger.Info("ready")
+		assert.Empty(t, w.String())
+
+		close(w.release)
+		logger.Flush()
+		assert.Equal(t, "level=info msg=started\nlevel=info msg=ready\n", w.String())
+		logger.Close()
+	})
+
+	t.Run("should drop logs if buffer is full", func(t *testing.T) {
+		w := newSlowWriter()
+		logger := newLogger(w, 1, true)
+
+		// the first log is being written, the second is buffered
+		logger.Info("first")
+		<-w.writing
+		logger.Info("second")
+		for i := 0; i < 10; i++ {
+			logger.Info("dropped")
+		}
+
+		close(w.release)
+		logger.Close()
+		assert.Equal(t, "level=info msg=first\nlevel=info msg=second\n", w.String())
+	})
+
+	t.Run("should write logs synchronously after close", func(t *testing.T) {
+		var b syncBuffer
+		logger := newLogger(&b, 10, false)
+
+		logger.Info("started")
+		logger.Close()
+		logger.Info("stopped")
+		logger.Flush()
+
+		assert.Equal(t, "level=info msg=started\nlevel=info msg=stopped\n", b.String())
+	})
+
+	t.Run("should write buffered logs before exiting on fatal", func(t *testing.T) {
+		w := newSlowWriter()
+		var written string
+		logger := log.NewLogrus(
+			log.LogrusWithWriter(w),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			log.LogrusWithAsyncWriter(10, false),
+			log.LogrusWithExitFunc(func(int) { written = w.String() }),
+		)
+
+		logger.Info("started")
+		close(w.release)
+		logger.Fatal("failed")
+
+		assert.Equal(t, "level=info msg=started\nlevel=fatal msg=failed\n", written)
+	})
+}
+
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLogrusClose(t *testing.T) {
+	t.Run("should flush buffered writer on close", func(t *testing.T) {
+		var b bytes.Buffer
+		w := bufio.NewWriter(&b)
+		logger := log.NewLogrus(log.LogrusWithWriter(w), log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}))
+
+		logger.Info("started")
+		assert.Empty(t, b.String())
+
+		assert.NoError(t, logger.Close())
+		assert.Equal(t, "level=info msg=started\n", b.String())
+	})
+
+	t.Run("should write async logs and close file on close", func(t *testing.T) {
+		f, err := ioutil.TempFile("", "logrus")
+		assert.NoError(t, err)
+		defer os.Remove(f.Name())
+
+		logger := log.NewLogrus(
+			log.LogrusWithWriter(f),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			log.LogrusWithAsyncWriter(10, false),
+		)
+		logger.Info("started")
+		logger.Info("stopped")
+		assert.NoError(t, logger.Close())
+
+		data, err := ioutil.ReadFile(f.Name())
+		assert.NoError(t, err)
+		assert.Equal(t, "level=info msg=started\nlevel=info msg=stopped\n", string(data))
+
+		_, err = f.WriteString("closed")
+		assert.Error(t, err)
+	})
+
+	t.Run("should close each route once", func(t *testing.T) {
+		acme, fallback := &closeRecorder{}, &closeRecorder{}
+		logger := log.NewLogrus(log.LogrusWithRouter("tenant", map[string]io.Writer{
+			"acme":   acme,
+			"acme-2": acme,
+		}, fallback))
+
+		assert.NoError(t, logger.Close())
+		assert.True(t, acme.closed)
+		assert.True(t, fallback.closed)
+	})
+
+	t.Run("should not close writers on close of logger with fields", func(t *testing.T) {
+		w := &closeRecorder{}
+		logger := log.NewLogrus(log.LogrusWithWriter(w), log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}))
+
+		child := logger.WithFields(map[string]interface{}{"component": "db"})
+		assert.NoError(t, child.Close())
+		assert.False(t, w.closed)
+
+		child.Info("connected")
+		assert.Equal(t, "level=info msg=connected component=db\n", w.String())
+
+		assert.NoError(t, logger.Close())
+		assert.True(t, w.closed)
+	})
+
+	t.Run("should not close stderr", func(t *testing.T) {
+		logger := log.NewLogrus()
+		assert.NoError(t, logger.Close())
+
+		_, err := os.Stderr.Write(nil)
+		assert.NoError(t, err)
+	})
+}
+
+func TestLogrusEnabled(t *testing.T) {
+	t.Run("should return true for levels at or above the configured level", func(t *testing.T) {
+		logger := log.NewLogrus(log.LogrusWithLevel("warn"))
+
+		assert.False(t, logger.Enabled("debug"))
+		assert.False(t, logger.Enabled("info"))
+		assert.True(t, logger.Enabled("warn"))
+		assert.True(t, logger.Enabled("error"))
+	})
+
+	t.Run("should return false for invalid level", func(t *testing.T) {
+		logger := log.NewLogrus(log.LogrusWithLevel("debug"))
+		assert.False(t, logger.Enabled("verbose"))
+	})
+}
+
+func TestLogrusWithLevelFromEnv(t *testing.T) {
+	setenv := func(t *testing.T, value string, ok bool) {
+		t.Helper()
+		prev, prevOK := os.LookupEnv("TEST_LOG_LEVEL")
+		if ok {
+			assert.NoError(t, os.Setenv("TEST_LOG_LEVEL", value))
+		} else {
+			assert.NoError(t, os.Unsetenv("TEST_LOG_LEVEL"))
+		}
+		t.Cleanup(func() {
+			if prevOK {
+				os.Setenv("TEST_LOG_LEVEL", prev)
+			} else {
+				os.Unsetenv("TEST_LOG_LEVEL")
+			}
+		})
+	}
+
+	t.Run("should set level from env", func(t *testing.T) {
+		setenv(t, "debug", true)
+		logger := log.NewLogrus(log.LogrusWithLevelFromEnv("TEST_LOG_LEVEL", "warn"))
+		assert.Equal(t, "debug", logger.Level())
+	})
+
+	t.Run("should fall back to default for invalid level", func(t *testing.T) {
+		setenv(t, "verbose", true)
+		logger := log.NewLogrus(log.LogrusWithLevelFromEnv("TEST_LOG_LEVEL", "warn"))
+		assert.Equal(t, "warning", logger.Level())
+	})
+
+	t.Run("should fall back to default if env is unset", func(t *testing.T) {
+		setenv(t, "", false)
+		logger := log.NewLogrus(log.LogrusWithLevelFromEnv("TEST_LOG_LEVEL", "error"))
+		assert.Equal(t, "error", logger.Level())
+	})
+
+	t.Run("should panic for invalid default", func(t *testing.T) {
+		setenv(t, "", false)
+		assert.Panics(t, func() {
+			log.NewLogrus(log.LogrusWithLevelFromEnv("TEST_LOG_LEVEL", "verbose"))
+		})
+	})
+}
+
+func TestLogrusWithCaller(t *testing.T) {
+	newLogger := func(b *bytes.Buffer, enabled bool) *log.Logrus {
+		return log.NewLogrus(
+			log.LogrusWithWriter(b),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			log.LogrusWithCaller(enabled),
+		)
+	}
+
+	t.Run("should add trimmed caller of the log", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b, true)
+
+		_, file, line, _ := runtime.Caller(0)
+		logger.Info("hello world", "foo", "bar")
+
+		caller := fmt.Sprintf("log/%s:%d", filepath.Base(file), line+1)
+		assert.Equal(t, fmt.Sprintf("level=info msg=\"hello world\" caller=\"%s\" foo=bar\n", caller), b.String())
+	})
+
+	t.Run("should add caller of entries", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b, true)
+
+		_, _, line, _ := runtime.Caller(0)
+		logger.Entry("foo", "bar").Warn("hello world")
+
+		assert.Contains(t, b.String(), fmt.Sprintf("caller=\"log/logrus_test.go:%d\"", line+1))
+	})
+
+	t.Run("should keep caller field set by the user", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b, true)
+
+		logger.Info("hello world", "caller", "grpc-client")
+		logger.WithFields(map[string]interface{}{"caller": "http-client"}).Info("hello world")
+
+		assert.Equal(t, "level=info msg=\"hello world\" caller=grpc-client\n"+
+			"level=info msg=\"hello world\" caller=http-client\n", b.String())
+	})
+
+	t.Run("should not add caller if disabled", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b, false)
+
+		logger.Info("hello world")
+		assert.Equal(t, "level=info msg=\"hello world\"\n", b.String())
+	})
+}
+
+func TestLogrusWithFields(t *testing.T) {
+	newLogger := func(b *bytes.Buffer) *log.Logrus {
+		return log.NewLogrus(
+			log.LogrusWithWriter(b),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+		)
+	}
+
+	t.Run("should add fields to every message", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b).WithFields(map[string]interface{}{"request_id": "123"})
+
+		logger.Info("started")
+		logger.Warn("slow request", "path", "/ping")
+
+		assert.Equal(t, "level=info msg=started request_id=123\n"+
+			"level=warning msg=\"slow request\" path=/ping request_id=123\n", b.String())
+	})
+
+	t.Run("should merge fields of parent and override with args", func(t *testing.T) {
+		var b bytes.Buffer
+		parent := newLogger(&b).WithFields(map[string]interface{}{"request_id": "123", "user": "foo"})
+		child := parent.WithFields(map[string]interface{}{"user": "bar"})
+
+		child.Info("started", "request_id", "456")
+		parent.Info("started")
+
+		assert.Equal(t, "level=info msg=started request_id=456 user=bar\n"+
+			"level=info msg=started request_id=123 user=foo\n", b.String())
+	})
+
+	t.Run("should not change parent logger", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := newLogger(&b)
+		logger.WithFields(map[string]interface{}{"request_id": "123"})
+
+		logger.Info("started")
+		assert.Equal(t, "level=info msg=started\n", b.String())
+	})
+}
+
+func TestLogrusWithFieldOrder(t *testing.T) {
+	t.Run("should write fields in the same order on every line", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := log.NewLogrus(
+			log.LogrusWithWriter(&b),
+			log.LogrusWithFieldOrder("request_id", "method", "path"),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+		)
+
+		logger.Info("request", "path", "/ping", "method", "GET", "request_id", "1", "status", 200)
+		logger.Info("request", "status", 404, "request_id", "2", "path", "/pong", "method", "POST", "bytes", 0)
+		logger.WithFields(map[string]interface{}{"path": "/ping"}).Info("request", "method", "GET", "request_id", "3")
+
+		assert.Equal(t, "level=info msg=request request_id=1 method=GET path=/ping status=200\n"+
+			"level=info msg=request request_id=2 method=POST path=/pong bytes=0 status=404\n"+
+			"level=info msg=request request_id=3 method=GET path=/ping\n", b.String())
+	})
+
+	t.Run("should keep timestamp and level in front", func(t *testing.T) {
+		var b bytes.Buffer
+		logger := log.NewLogrus(
+			log.LogrusWithWriter(&b),
+			log.LogrusWithFieldOrder("zone", "app"),
+			log.LogrusWithFormatter(&logrus.TextFormatter{
+				FieldMap: logrus.FieldMap{logrus.FieldKeyTime: "ts"},
+			}),
+		)
+
+		logger.Warn("slow", "app", "salt", "zone", "a")
+
+		assert.Regexp(t, `^ts=\S+ level=warning msg=slow zone=a app=salt\n$`, b.String())
+	})
+}
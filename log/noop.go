@@ -16,9 +16,18 @@ func (n *Noop) Fatal(msg string, args ...interface{}) {}
 func (n *Noop) Level() string {
 	return "unsupported"
 }
+func (n *Noop) Enabled(level string) bool {
+	return false
+}
+func (n *Noop) WithFields(fields map[string]interface{}) Logger {
+	return n
+}
 func (n *Noop) Writer() io.Writer {
 	return ioutil.Discard
 }
+func (n *Noop) Close() error {
+	return nil
+}
 
 // NewNoop returns a no operation logger, useful in tests
 func NewNoop(opts ...Option) *Noop {
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fieldOrder sorts the fields listed in keys first, in the same order,
+// followed by the rest of the fields in alphabetical order. The keys
+// logrus adds itself, e.g. `time`, `level` and `msg`, are kept in front.
+func fieldOrder(f *logrus.TextFormatter, keys []string) func([]string) {
+	fixed := map[string]bool{}
+	for key, name := range map[string]string{
+		logrus.FieldKeyTime:        f.FieldMap[logrus.FieldKeyTime],
+		logrus.FieldKeyLevel:       f.FieldMap[logrus.FieldKeyLevel],
+		logrus.FieldKeyMsg:         f.FieldMap[logrus.FieldKeyMsg],
+		logrus.FieldKeyLogrusError: f.FieldMap[logrus.FieldKeyLogrusError],
+		logrus.FieldKeyFunc:        f.FieldMap[logrus.FieldKeyFunc],
+		logrus.FieldKeyFile:        f.FieldMap[logrus.FieldKeyFile],
+	} {
+		if name == "" {
+			name = key
+		}
+		fixed[name] = true
+	}
+
+	rank := make(map[string]int, len(keys))
+	for i, key := range keys {
+		rank[key] = i + 1
+	}
+	rankOf := func(key string) int {
+		if fixed[key] {
+			return 0
+		}
+		if r, ok := rank[key]; ok {
+			return r
+		}
+		return len(keys) + 1
+	}
+
+	return func(fields []string) {
+		sort.SliceStable(fields, func(i, j int) bool {
+			ri, rj := rankOf(fields[i]), rankOf(fields[j])
+			if ri != rj {
+				return ri < rj
+			}
+			// fixed keys keep the order logrus adds them in
+			return ri > len(keys) && fields[i] < fields[j]
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	otlpBatchSize      = 512
+	otlpExportInterval = time.Second
+	otlpExportTimeout  = 10 * time.Second
+	otlpScopeName      = "github.com/odpf/salt/log"
+)
+
+// otlpSeverities maps the levels to the OTLP severity numbers
+var otlpSeverities = map[logrus.Level]int{
+	logrus.TraceLevel: 1,
+	logrus.DebugLevel: 5,
+	logrus.InfoLevel:  9,
+	logrus.WarnLevel:  13,
+	logrus.ErrorLevel: 17,
+	logrus.FatalLevel: 21,
+	logrus.PanicLevel: 24,
+}
+
+// types of the OTLP/HTTP JSON encoding of ExportLogsServiceRequest,
+// 64 bit integers are encoded as strings
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func newOTLPLogRecord(entry *logrus.Entry) otlpLogRecord {
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attributes := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		attributes = append(attributes, otlpKeyValue{Key: k, Value: newOTLPAnyValue(entry.Data[k])})
+	}
+
+	return otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(entry.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       otlpSeverities[entry.Level],
+		SeverityText:         entry.Level.String(),
+		Body:                 newOTLPAnyValue(entry.Message),
+		Attributes:           attributes,
+	}
+}
+
+func newOTLPAnyValue(value interface{}) otlpAnyValue {
+	var intValue int64
+	switch v := value.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case float32:
+		f := float64(v)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	case int:
+		intValue = int64(v)
+	case int8:
+		intValue = int64(v)
+	case int16:
+		intValue = int64(v)
+	case int32:
+		intValue = int64(v)
+	case int64:
+		intValue = v
+	case uint8:
+		intValue = int64(v)
+	case uint16:
+		intValue = int64(v)
+	case uint32:
+		intValue = int64(v)
+	case uint:
+		intValue = clampInt64(uint64(v))
+	case uint64:
+		intValue = clampInt64(v)
+	case uintptr:
+		intValue = clampInt64(uint64(v))
+	case error:
+		s := v.Error()
+		return otlpAnyValue{StringValue: &s}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+	s := strconv.FormatInt(intValue, 10)
+	return otlpAnyValue{IntValue: &s}
+}
+
+// clampInt64 returns the value, or the max int64 if it overflows
+// the signed int of the otlp int values
+func clampInt64(v uint64) int64 {
+	if v > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(v)
+}
+
+// otlpExporter is a hook exporting the entries to an OTLP/HTTP
+// collector in batches, in the background
+type otlpExporter struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	records []otlpLogRecord
+	closed  bool
+
+	// exports are serialized so the batches are sent in order
+	exportMu sync.Mutex
+
+	full      chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func newOTLPExporter(endpoint string) *otlpExporter {
+	e := &otlpExporter{
+		url:     strings.TrimSuffix(endpoint, "/") + "/v1/logs",
+		client:  &http.Client{Timeout: otlpExportTimeout},
+		full:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *otlpExporter) run() {
+	defer close(e.stopped)
+
+	ticker := time.NewTicker(otlpExportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+		case <-e.full:
+		}
+		if err := e.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export logs: %v\n", err)
+		}
+	}
+}
+
+func (e *otlpExporter) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (e *otlpExporter) Fire(entry *logrus.Entry) error {
+	record := newOTLPLogRecord(entry)
+
+	e.mu.Lock()
+	e.records = append(e.records, record)
+	n, closed := len(e.records), e.closed
+	e.mu.Unlock()
+
+	// there is no background export once closed
+	if closed {
+		return e.Flush()
+	}
+	if n >= otlpBatchSize {
+		select {
+		case e.full <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush exports the buffered records
+func (e *otlpExporter) Flush() error {
+	e.exportMu.Lock()
+	defer e.exportMu.Unlock()
+
+	e.mu.Lock()
+	records := e.records
+	e.records = nil
+	e.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+	return e.export(records)
+}
+
+// Close stops the background export and exports the buffered records
+func (e *otlpExporter) Close() error {
+	e.closeOnce.Do(func() {
+		e.mu.Lock()
+		e.closed = true
+		e.mu.Unlock()
+
+		close(e.done)
+		<-e.stopped
+	})
+	return e.Flush()
+}
+
+func (e *otlpExporter) export(records []otlpLogRecord) error {
+	body, err := json.Marshal(otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: otlpScopeName},
+				LogRecords: records,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding logs: %w", err)
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("exporting logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("exporting logs: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// LogrusWithOTLPExport exports the logs to the OTLP/HTTP collector at the
+// endpoint, e.g. `http://localhost:4318`, along with writing them. The
+// level is exported as the severity, the message as the body and the
+// fields as the attributes. Logs are exported in batches in the background,
+// buffered logs are exported on Flush, Close and before exiting on Fatal.
+func LogrusWithOTLPExport(endpoint string) Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).otlpEndpoint = endpoint
+	}
+}
@@ -0,0 +1,139 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/odpf/salt/log"
+	"github.com/sirupsen/logrus"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type otlpCollector struct {
+	mu       sync.Mutex
+	requests []map[string]interface{}
+	paths    []string
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	c.paths = append(c.paths, r.URL.Path)
+}
+
+func (c *otlpCollector) records() []map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var records []map[string]interface{}
+	for _, req := range c.requests {
+		for _, rl := range req["resourceLogs"].([]interface{}) {
+			for _, sl := range rl.(map[string]interface{})["scopeLogs"].([]interface{}) {
+				for _, r := range sl.(map[string]interface{})["logRecords"].([]interface{}) {
+					records = append(records, r.(map[string]interface{}))
+				}
+			}
+		}
+	}
+	return records
+}
+
+func TestLogrusWithOTLPExport(t *testing.T) {
+	t.Run("should export logs as otlp log records on flush", func(t *testing.T) {
+		collector := &otlpCollector{}
+		srv := httptest.NewServer(collector)
+		defer srv.Close()
+
+		logger := log.NewLogrus(log.LogrusWithWriter(ioutil.Discard), log.LogrusWithOTLPExport(srv.URL))
+		defer logger.Close()
+
+		logger.Warn("disk almost full", "disk", "sda1", "usage", 93, "ratio", 0.93, "critical", false)
+		logger.Flush()
+
+		records := collector.records()
+		if assert.Len(t, records, 1) {
+			r := records[0]
+			assert.Equal(t, float64(13), r["severityNumber"])
+			assert.Equal(t, "warning", r["severityText"])
+			assert.Equal(t, map[string]interface{}{"stringValue": "disk almost full"}, r["body"])
+			assert.NotEmpty(t, r["timeUnixNano"])
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"key": "critical", "value": map[string]interface{}{"boolValue": false}},
+				map[string]interface{}{"key": "disk", "value": map[string]interface{}{"stringValue": "sda1"}},
+				map[string]interface{}{"key": "ratio", "value": map[string]interface{}{"doubleValue": 0.93}},
+				map[string]interface{}{"key": "usage", "value": map[string]interface{}{"intValue": "93"}},
+			}, r["attributes"])
+		}
+		assert.Equal(t, []string{"/v1/logs"}, collector.paths)
+	})
+	t.Run("should export unsigned ints as int values", func(t *testing.T) {
+		collector := &otlpCollector{}
+		srv := httptest.NewServer(collector)
+		defer srv.Close()
+
+		logger := log.NewLogrus(log.LogrusWithWriter(ioutil.Discard), log.LogrusWithOTLPExport(srv.URL))
+		defer logger.Close()
+
+		logger.Info("sizes", "files", uint(7), "bytes", uint64(1024), "max", uint64(math.MaxUint64), "addr", uintptr(42))
+		logger.Flush()
+
+		records := collector.records()
+		if assert.Len(t, records, 1) {
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"key": "addr", "value": map[string]interface{}{"intValue": "42"}},
+				map[string]interface{}{"key": "bytes", "value": map[string]interface{}{"intValue": "1024"}},
+				map[string]interface{}{"key": "files", "value": map[string]interface{}{"intValue": "7"}},
+				map[string]interface{}{"key": "max", "value": map[string]interface{}{"intValue": "9223372036854775807"}},
+			}, records[0]["attributes"])
+		}
+	})
+	t.Run("should export buffered logs in one batch on close", func(t *testing.T) {
+		collector := &otlpCollector{}
+		srv := httptest.NewServer(collector)
+		defer srv.Close()
+
+		logger := log.NewLogrus(log.LogrusWithWriter(ioutil.Discard), log.LogrusWithOTLPExport(srv.URL+"/"))
+		logger.Info("first")
+		logger.Error("second")
+		logger.Close()
+
+		records := collector.records()
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, float64(9), records[0]["severityNumber"])
+			assert.Equal(t, float64(17), records[1]["severityNumber"])
+		}
+		assert.Len(t, collector.requests, 1)
+	})
+	t.Run("should write and export logs synchronously after close", func(t *testing.T) {
+		collector := &otlpCollector{}
+		srv := httptest.NewServer(collector)
+		defer srv.Close()
+
+		var b bytes.Buffer
+		logger := log.NewLogrus(
+			log.LogrusWithWriter(&b),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			log.LogrusWithAsyncWriter(10, false),
+			log.LogrusWithOTLPExport(srv.URL),
+		)
+		assert.NoError(t, logger.Close())
+		logger.Info("after close")
+
+		assert.Equal(t, "level=info msg=\"after close\"\n", b.String())
+		assert.Len(t, collector.records(), 1)
+	})
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// routerHook writes each entry to the writer of its field value
+type routerHook struct {
+	field    string
+	routes   map[string]io.Writer
+	fallback io.Writer
+
+	mu sync.Mutex
+}
+
+func (h *routerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *routerHook) Fire(entry *logrus.Entry) error {
+	w := h.fallback
+	if value, ok := entry.Data[h.field]; ok {
+		if route, ok := h.routes[fmt.Sprint(value)]; ok {
+			w = route
+		}
+	}
+	if w == nil {
+		return nil
+	}
+
+	line, err := entry.Logger.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = w.Write(line)
+	return err
+}
+
+// LogrusWithRouter writes each log to the writer of the route matching
+// the value of the field, e.g. a file per tenant, instead of the writer
+// of the logger. Logs without the field or with a value with no route
+// are written to the fallback, or dropped if it is nil.
+// For example:
+//   l := log.NewLogrus(log.LogrusWithRouter("tenant", map[string]io.Writer{
+//       "acme": acmeFile,
+//       "initech": initechFile,
+//   }, os.Stderr))
+func LogrusWithRouter(field string, routes map[string]io.Writer, fallback io.Writer) Option {
+	return func(logger interface{}) {
+		logger.(*Logrus).router = &routerHook{
+			field:    field,
+			routes:   routes,
+			fallback: fallback,
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package log_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/odpf/salt/log"
+)
+
+func TestLogrusWithRouter(t *testing.T) {
+	newLogger := func(routes map[string]io.Writer, fallback io.Writer, out io.Writer) *log.Logrus {
+		return log.NewLogrus(
+			log.LogrusWithWriter(out),
+			log.LogrusWithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+			log.LogrusWithRouter("tenant", routes, fallback),
+		)
+	}
+
+	t.Run("should write logs to the route of the field value", func(t *testing.T) {
+		var acme, initech, fallback, out bytes.Buffer
+		logger := newLogger(map[string]io.Writer{"acme": &acme, "initech": &initech}, &fallback, &out)
+
+		logger.Info("created", "tenant", "acme")
+		logger.Warn("slow", "tenant", "initech")
+		logger.WithFields(map[string]interface{}{"tenant": "acme"}).Error("failed")
+		logger.Info("started")
+		logger.Info("created", "tenant", "globex")
+
+		assert.Equal(t, "level=info msg=created tenant=acme\nlevel=error msg=failed tenant=acme\n", acme.String())
+		assert.Equal(t, "level=warning msg=slow tenant=initech\n", initech.String())
+		assert.Equal(t, "level=info msg=started\nlevel=info msg=created tenant=globex\n", fallback.String())
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("should match non string field values", func(t *testing.T) {
+		var first, fallback bytes.Buffer
+		logger := newLogger(map[string]io.Writer{"1": &first}, &fallback, &bytes.Buffer{})
+
+		logger.Info("created", "tenant", 1)
+
+		assert.Equal(t, "level=info msg=created tenant=1\n", first.String())
+		assert.Empty(t, fallback.String())
+	})
+
+	t.Run("should drop logs without route if fallback is nil", func(t *testing.T) {
+		var acme, out bytes.Buffer
+		logger := newLogger(map[string]io.Writer{"acme": &acme}, nil, &out)
+
+		logger.Info("started")
+
+		assert.Empty(t, acme.String())
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("should not write logs filtered by level", func(t *testing.T) {
+		var acme bytes.Buffer
+		logger := newLogger(map[string]io.Writer{"acme": &acme}, nil, &bytes.Buffer{})
+
+		logger.Debug("query", "tenant", "acme")
+
+		assert.Empty(t, acme.String())
+	})
+}
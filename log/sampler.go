@@ -0,0 +1,76 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+type samplerKey struct {
+	level string
+	msg   string
+}
+
+type samplerCount struct {
+	resetAt time.Time
+	n       int
+}
+
+// sampler allows the first logs with the same level and message
+// in each tick and every thereafter-th log after that.
+type sampler struct {
+	mu         sync.Mutex
+	tick       time.Duration
+	first      int
+	thereafter int
+	counts     map[samplerKey]*samplerCount
+
+	// expired counts are pruned at most once per tick
+	// so new keys do not scan all the counts
+	pruneAt time.Time
+}
+
+func newSampler(tick time.Duration, first, thereafter int) *sampler {
+	return &sampler{
+		tick:       tick,
+		first:      first,
+		thereafter: thereafter,
+		counts:     map[samplerKey]*samplerCount{},
+	}
+}
+
+func (s *sampler) allow(level, msg string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.After(s.pruneAt) {
+		s.prune(now)
+		s.pruneAt = now.Add(s.tick)
+	}
+
+	key := samplerKey{level: level, msg: msg}
+	c, ok := s.counts[key]
+	if !ok || now.After(c.resetAt) {
+		c = &samplerCount{resetAt: now.Add(s.tick)}
+		s.counts[key] = c
+	}
+
+	c.n++
+	if c.n <= s.first {
+		return true
+	}
+	if s.thereafter <= 0 {
+		return false
+	}
+	return (c.n-s.first)%s.thereafter == 0
+}
+
+// prune removes counts of expired ticks so messages
+// seen once do not accumulate
+func (s *sampler) prune(now time.Time) {
+	for k, c := range s.counts {
+		if now.After(c.resetAt) {
+			delete(s.counts, k)
+		}
+	}
+}
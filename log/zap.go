@@ -1,44 +1,119 @@
 package log
 
 import (
+	"errors"
 	"io"
+	"syscall"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type Zap struct {
-	log  *zap.SugaredLogger
-	conf zap.Config
+	log     *zap.SugaredLogger
+	conf    zap.Config
+	encoder ValueEncoder
+}
+
+// fields returns the key/value arguments of a message as zap fields
+func (z Zap) fields(args []interface{}) []interface{} {
+	args = expandFields(args)
+	if z.encoder != nil {
+		args = encodeValues(args, z.encoder)
+	}
+	return args
 }
 
 func (z Zap) Debug(msg string, args ...interface{}) {
-	z.log.With(args...).Debug(msg)
+	z.log.With(z.fields(args)...).Debug(msg)
 }
 
 func (z Zap) Info(msg string, args ...interface{}) {
-	z.log.With(args...).Info(msg)
+	z.log.With(z.fields(args)...).Info(msg)
 }
 
 func (z Zap) Warn(msg string, args ...interface{}) {
-	z.log.With(args...).Warn(msg, args)
+	z.log.With(z.fields(args)...).Warn(msg)
 }
 
 func (z Zap) Error(msg string, args ...interface{}) {
-	z.log.With(args...).Error(msg, args)
+	z.log.With(z.fields(args)...).Error(msg)
 }
 
 func (z Zap) Fatal(msg string, args ...interface{}) {
-	z.log.With(args...).Fatal(msg, args)
+	z.log.With(z.fields(args)...).Fatal(msg)
 }
 
 func (z Zap) Level() string {
 	return z.conf.Level.String()
 }
 
+// Enabled returns true if messages at the level are logged,
+// it returns false for an invalid level.
+func (z Zap) Enabled(level string) bool {
+	if level == "warning" {
+		level = "warn"
+	}
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return false
+	}
+	return z.log.Desugar().Core().Enabled(lvl)
+}
+
+// WithFields returns a logger sharing the configuration of z
+// which adds the fields to every message
+func (z Zap) WithFields(fields map[string]interface{}) Logger {
+	args := make([]interface{}, 0, len(fields)*2)
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+	if z.encoder != nil {
+		args = encodeValues(args, z.encoder)
+	}
+	return &Zap{
+		log:     z.log.With(args...),
+		conf:    z.conf,
+		encoder: z.encoder,
+	}
+}
+
 func (z Zap) Writer() io.Writer {
 	panic("not supported")
 }
 
+// Close flushes the buffered logs of the outputs, the outputs
+// themselves are closed by zap when the process exits. The sync
+// errors of stdout and stderr, e.g. of a terminal, are ignored.
+func (z Zap) Close() error {
+	err := z.log.Sync()
+	if err == nil || !z.writesToStd() {
+		return err
+	}
+
+	// zap combines the errors of the outputs
+	errs := []error{err}
+	if m, ok := err.(interface{ Errors() []error }); ok {
+		errs = m.Errors()
+	}
+	for _, e := range errs {
+		if !errors.Is(e, syscall.EINVAL) && !errors.Is(e, syscall.ENOTTY) {
+			return err
+		}
+	}
+	return nil
+}
+
+// writesToStd returns true if stdout or stderr are outputs of the logger
+func (z Zap) writesToStd() bool {
+	for _, p := range z.conf.OutputPaths {
+		if p == "stdout" || p == "stderr" {
+			return true
+		}
+	}
+	return false
+}
+
 func ZapWithConfig(conf zap.Config, opts ...zap.Option) Option {
 	return func(z interface{}) {
 		z.(*Zap).conf = conf
@@ -3,9 +3,13 @@ package log_test
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,6 +22,9 @@ import (
 
 const bufWriterKey = "zapBufWriter"
 
+// sinks can be registered only once per key
+var bufWriterCount int
+
 type zapBufWriter struct {
 	io.Writer
 }
@@ -45,11 +52,13 @@ func buildBufferedZapOption(writer io.Writer, t time.Time) log.Option {
 	config := zap.NewDevelopmentConfig()
 	config.DisableCaller = true
 	// register mock writer
-	_ = zap.RegisterSink(bufWriterKey, func(u *url.URL) (zap.Sink, error) {
+	bufWriterCount++
+	key := fmt.Sprintf("%s%d", bufWriterKey, bufWriterCount)
+	_ = zap.RegisterSink(key, func(u *url.URL) (zap.Sink, error) {
 		return zapBufWriter{writer}, nil
 	})
 	// build a valid custom path
-	customPath := fmt.Sprintf("%s:", bufWriterKey)
+	customPath := fmt.Sprintf("%s:", key)
 	config.OutputPaths = []string{customPath}
 
 	return log.ZapWithConfig(config, zap.WithClock(&zapClock{
@@ -71,3 +80,111 @@ func TestZap(t *testing.T) {
 		assert.Equal(t, mockedTime.Format("2006-01-02T15:04:05.000Z0700")+"\tINFO\thello\t{\"wor\": \"ld\"}\n", b.String())
 	})
 }
+
+func TestZapErr(t *testing.T) {
+	mockedTime := time.Date(2021, 6, 10, 11, 55, 0, 0, time.UTC)
+
+	t.Run("should add error fields", func(t *testing.T) {
+		var b bytes.Buffer
+		bWriter := bufio.NewWriter(&b)
+
+		zapper := log.NewZap(buildBufferedZapOption(bWriter, mockedTime))
+		zapper.Error("request failed", log.Err(fmt.Errorf("fetching user: %w", errors.New("connection refused"))))
+		bWriter.Flush()
+
+		// development config adds a stack trace to error logs
+		line := strings.SplitN(b.String(), "\n", 2)[0]
+		assert.Equal(t, mockedTime.Format("2006-01-02T15:04:05.000Z0700")+
+			"\tERROR\trequest failed\t"+
+			`{"error": "fetching user: connection refused", "error_type": "*fmt.wrapError", "causes": ["connection refused"]}`, line)
+	})
+}
+
+func TestZapEnabled(t *testing.T) {
+	t.Run("should return true for levels at or above the configured level", func(t *testing.T) {
+		zapper := log.NewZap()
+
+		assert.False(t, zapper.Enabled("debug"))
+		assert.True(t, zapper.Enabled("info"))
+		assert.True(t, zapper.Enabled("warning"))
+		assert.True(t, zapper.Enabled("error"))
+	})
+
+	t.Run("should return false for noop logger and invalid level", func(t *testing.T) {
+		assert.False(t, log.NewZap(log.ZapWithNoop()).Enabled("error"))
+		assert.False(t, log.NewZap().Enabled("verbose"))
+	})
+}
+
+func TestZapWithFields(t *testing.T) {
+	mockedTime := time.Date(2021, 6, 10, 11, 55, 0, 0, time.UTC)
+
+	t.Run("should add fields to every message", func(t *testing.T) {
+		var b bytes.Buffer
+		bWriter := bufio.NewWriter(&b)
+
+		zapper := log.NewZap(buildBufferedZapOption(bWriter, mockedTime))
+		zapper.WithFields(map[string]interface{}{"request_id": "123"}).Info("hello", "wor", "ld")
+		bWriter.Flush()
+
+		assert.Equal(t, mockedTime.Format("2006-01-02T15:04:05.000Z0700")+"\tINFO\thello\t{\"request_id\": \"123\", \"wor\": \"ld\"}\n", b.String())
+	})
+}
+
+func TestZapWithValueEncoder(t *testing.T) {
+	mockedTime := time.Date(2021, 6, 10, 11, 55, 0, 0, time.UTC)
+
+	t.Run("should encode values of fields", func(t *testing.T) {
+		var b bytes.Buffer
+		bWriter := bufio.NewWriter(&b)
+
+		zapper := log.NewZap(buildBufferedZapOption(bWriter, mockedTime), log.WithValueEncoder(func(v interface{}) interface{} {
+			if at, ok := v.(time.Time); ok {
+				return at.Format(time.RFC3339)
+			}
+			return v
+		}))
+		zapper.WithFields(map[string]interface{}{"created_at": mockedTime}).Info("hello", "updated_at", mockedTime)
+		bWriter.Flush()
+
+		assert.Equal(t, mockedTime.Format("2006-01-02T15:04:05.000Z0700")+
+			"\tINFO\thello\t{\"created_at\": \"2021-06-10T11:55:00Z\", \"updated_at\": \"2021-06-10T11:55:00Z\"}\n", b.String())
+	})
+}
+
+func TestZapClose(t *testing.T) {
+	t.Run("should sync outputs on close", func(t *testing.T) {
+		f, err := ioutil.TempFile("", "zap")
+		assert.NoError(t, err)
+		f.Close()
+		defer os.Remove(f.Name())
+
+		config := zap.NewProductionConfig()
+		config.OutputPaths = []string{f.Name()}
+		zapper := log.NewZap(log.ZapWithConfig(config))
+
+		zapper.Info("started")
+		assert.NoError(t, zapper.Close())
+
+		data, err := ioutil.ReadFile(f.Name())
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"msg":"started"`)
+	})
+
+	t.Run("should ignore sync error of stdout and stderr", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		assert.NoError(t, err)
+		defer r.Close()
+		defer w.Close()
+
+		stderr := os.Stderr
+		os.Stderr = w
+		defer func() { os.Stderr = stderr }()
+
+		config := zap.NewProductionConfig()
+		config.OutputPaths = []string{"stderr"}
+		zapper := log.NewZap(log.ZapWithConfig(config))
+
+		assert.NoError(t, zapper.Close())
+	})
+}
@@ -0,0 +1,74 @@
+package printer
+
+import (
+	"io"
+
+	"github.com/muesli/termenv"
+	"github.com/odpf/salt/term"
+)
+
+// colorProfile is ascii, i.e. without colors, when stdout is
+// not a terminal or NO_COLOR is set.
+var colorProfile = termenv.EnvColorProfile()
+
+// colorsSet is true once colors are enabled or disabled, the
+// output written to a given writer follows colorProfile then.
+var colorsSet bool
+
+// DisableColors makes the color helpers return plain text.
+func DisableColors() {
+	colorProfile = termenv.Ascii
+	colorsSet = true
+}
+
+// EnableColors makes the color helpers return colored text
+// even when stdout is not a terminal.
+func EnableColors() {
+	colorProfile = termenv.ANSI
+	colorsSet = true
+}
+
+func Green(s string) string {
+	return color(s, "2")
+}
+
+func Red(s string) string {
+	return color(s, "1")
+}
+
+func Yellow(s string) string {
+	return color(s, "3")
+}
+
+func Cyan(s string) string {
+	return color(s, "6")
+}
+
+func Bold(s string) string {
+	if colorProfile == termenv.Ascii {
+		return s
+	}
+	return termenv.String(s).Bold().String()
+}
+
+func color(s string, c string) string {
+	return profileColor(colorProfile, s, c)
+}
+
+func profileColor(p termenv.Profile, s string, c string) string {
+	return termenv.String(s).Foreground(p.Color(c)).String()
+}
+
+// writerColorProfile returns the color profile of the output written
+// to w, which is colored if w is a terminal and NO_COLOR is not set,
+// unless colors are enabled or disabled
+func writerColorProfile(w io.Writer) termenv.Profile {
+	switch {
+	case colorsSet:
+		return colorProfile
+	case term.IsTerminal(w) && !term.IsColorDisabled():
+		return termenv.ANSI
+	default:
+		return termenv.Ascii
+	}
+}
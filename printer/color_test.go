@@ -0,0 +1,52 @@
+package printer_test
+
+import (
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+// withColors enables or disables the colors for the test
+// and restores the previous setting once the test is done
+func withColors(t *testing.T, enabled bool) {
+	t.Helper()
+
+	profile := termenv.Ascii
+	if enabled {
+		profile = termenv.ANSI
+	}
+	t.Cleanup(printer.SetColorProfile(profile))
+}
+
+func TestColors(t *testing.T) {
+	t.Run("should add color codes when enabled", func(t *testing.T) {
+		withColors(t, true)
+
+		assert.Equal(t, "\x1b[32mok\x1b[0m", printer.Green("ok"))
+		assert.Equal(t, "\x1b[31mfailed\x1b[0m", printer.Red("failed"))
+		assert.Equal(t, "\x1b[33mwarning\x1b[0m", printer.Yellow("warning"))
+		assert.Equal(t, "\x1b[36minfo\x1b[0m", printer.Cyan("info"))
+		assert.Equal(t, "\x1b[1mtitle\x1b[0m", printer.Bold("title"))
+	})
+
+	t.Run("should return plain text when disabled", func(t *testing.T) {
+		withColors(t, false)
+
+		assert.Equal(t, "ok", printer.Green("ok"))
+		assert.Equal(t, "failed", printer.Red("failed"))
+		assert.Equal(t, "warning", printer.Yellow("warning"))
+		assert.Equal(t, "info", printer.Cyan("info"))
+		assert.Equal(t, "title", printer.Bold("title"))
+	})
+
+	t.Run("should make colors helpers follow disabling and enabling", func(t *testing.T) {
+		withColors(t, false)
+
+		printer.EnableColors()
+		assert.Equal(t, termenv.ANSI, printer.ColorProfile())
+		printer.DisableColors()
+		assert.Equal(t, termenv.Ascii, printer.ColorProfile())
+	})
+}
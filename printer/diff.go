@@ -0,0 +1,208 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes
+const diffContext = 3
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// Diff writes a line based unified diff of old and new, e.g. to show
+// the changes of a plan, with added lines in green and removed lines
+// in red if w is a terminal, see EnableColors and DisableColors to
+// override it. Nothing is written if they are equal.
+func Diff(w io.Writer, old, new string) error {
+	profile := writerColorProfile(w)
+	lines := diffLines(splitLines(old), splitLines(new))
+
+	var sb strings.Builder
+	for _, h := range diffHunks(lines) {
+		oldStart, oldCount, newStart, newCount := hunkRange(lines, h[0], h[1])
+		sb.WriteString(profileColor(profile, fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount), "6"))
+		sb.WriteString("\n")
+
+		for _, l := range lines[h[0]:h[1]] {
+			s := string(l.op) + l.text
+			switch l.op {
+			case '+':
+				s = profileColor(profile, s, "2")
+			case '-':
+				s = profileColor(profile, s, "1")
+			}
+			sb.WriteString(s)
+			sb.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the lines of a and b in order, marking the ones
+// not in their longest common subsequence as removed or added
+func diffLines(a, b []string) []diffLine {
+	// the unchanged lines around the changes are kept out of
+	// the subsequence search, usually most of the lines
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []diffLine
+	for _, l := range a[:prefix] {
+		lines = append(lines, diffLine{' ', l})
+	}
+	lines = lcsLines(lines, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, l := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', l})
+	}
+	return lines
+}
+
+// lcsLines appends the diff lines of a and b to lines, splitting a in
+// halves at the line of b the longest common subsequence goes through
+// (Hirschberg's algorithm), so the memory used is linear
+func lcsLines(lines []diffLine, a, b []string) []diffLine {
+	switch {
+	case len(a) == 0:
+		for _, l := range b {
+			lines = append(lines, diffLine{'+', l})
+		}
+		return lines
+	case len(b) == 0:
+		for _, l := range a {
+			lines = append(lines, diffLine{'-', l})
+		}
+		return lines
+	case len(a) == 1:
+		for j, l := range b {
+			if l == a[0] {
+				lines = lcsLines(lines, nil, b[:j])
+				lines = append(lines, diffLine{' ', l})
+				return lcsLines(lines, nil, b[j+1:])
+			}
+		}
+		lines = append(lines, diffLine{'-', a[0]})
+		return lcsLines(lines, nil, b)
+	}
+
+	mid := len(a) / 2
+	head := lcsLengths(a[:mid], b, false)
+	tail := lcsLengths(a[mid:], b, true)
+
+	// the first split with the longest subsequence, so that
+	// removed lines come before the added ones
+	split := 0
+	for j := range head {
+		if head[j]+tail[j] > head[split]+tail[split] {
+			split = j
+		}
+	}
+
+	lines = lcsLines(lines, a[:mid], b[:split])
+	return lcsLines(lines, a[mid:], b[split:])
+}
+
+// lcsLengths returns the lengths of the longest common subsequences of
+// a and b[:j] for each j, or of a and b[j:] if reverse, keeping only
+// two rows of the table
+func lcsLengths(a, b []string, reverse bool) []int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		if reverse {
+			ai := a[len(a)-1-i]
+			for j := len(b) - 1; j >= 0; j-- {
+				if ai == b[j] {
+					cur[j] = prev[j+1] + 1
+				} else if prev[j] >= cur[j+1] {
+					cur[j] = prev[j]
+				} else {
+					cur[j] = cur[j+1]
+				}
+			}
+		} else {
+			for j := 1; j <= len(b); j++ {
+				if a[i] == b[j-1] {
+					cur[j] = prev[j-1] + 1
+				} else if prev[j] >= cur[j-1] {
+					cur[j] = prev[j]
+				} else {
+					cur[j] = cur[j-1]
+				}
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// diffHunks returns the [start, end) ranges of the changed lines with
+// their context, changes closer than twice the context are joined
+func diffHunks(lines []diffLine) [][2]int {
+	var hunks [][2]int
+	for i, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		start, end := i-diffContext, i+1+diffContext
+		if start < 0 {
+			start = 0
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+		if n := len(hunks); n > 0 && start <= hunks[n-1][1] {
+			hunks[n-1][1] = end
+			continue
+		}
+		hunks = append(hunks, [2]int{start, end})
+	}
+	return hunks
+}
+
+// hunkRange returns the 1-based start line and the number of lines of
+// the hunk in old and new, the start is the line before an empty range
+func hunkRange(lines []diffLine, start, end int) (oldStart, oldCount, newStart, newCount int) {
+	for _, l := range lines[:start] {
+		if l.op != '+' {
+			oldStart++
+		}
+		if l.op != '-' {
+			newStart++
+		}
+	}
+	for _, l := range lines[start:end] {
+		if l.op != '+' {
+			oldCount++
+		}
+		if l.op != '-' {
+			newCount++
+		}
+	}
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+	return oldStart, oldCount, newStart, newCount
+}
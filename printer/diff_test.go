@@ -0,0 +1,86 @@
+package printer_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("should mark removed and added lines", func(t *testing.T) {
+		var b bytes.Buffer
+		err := printer.Diff(&b, "name: app\nreplicas: 1\nimage: app:v1\n", "name: app\nreplicas: 3\nimage: app:v1\n")
+		assert.NoError(t, err)
+		assert.Equal(t, "@@ -1,3 +1,3 @@\n name: app\n-replicas: 1\n+replicas: 3\n image: app:v1\n", b.String())
+	})
+
+	t.Run("should split distant changes into hunks with context", func(t *testing.T) {
+		var b bytes.Buffer
+		err := printer.Diff(&b, "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", "A\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n")
+		assert.NoError(t, err)
+		assert.Equal(t, "@@ -1,4 +1,4 @@\n-a\n+A\n b\n c\n d\n@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n", b.String())
+	})
+
+	t.Run("should diff reordered lines of large inputs", func(t *testing.T) {
+		var old, new strings.Builder
+		for i := 0; i < 5000; i++ {
+			fmt.Fprintf(&old, "line %d\n", i)
+			fmt.Fprintf(&new, "line %d\n", 4999-i)
+		}
+
+		var b bytes.Buffer
+		assert.NoError(t, printer.Diff(&b, old.String(), new.String()))
+		assert.Equal(t, 4999, strings.Count(b.String(), "\n-line"))
+		assert.Equal(t, 4999, strings.Count(b.String(), "\n+line"))
+	})
+
+	t.Run("should handle empty inputs", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, printer.Diff(&b, "", "a\nb"))
+		assert.Equal(t, "@@ -0,0 +1,2 @@\n+a\n+b\n", b.String())
+
+		b.Reset()
+		assert.NoError(t, printer.Diff(&b, "a\n", ""))
+		assert.Equal(t, "@@ -1,1 +0,0 @@\n-a\n", b.String())
+
+		b.Reset()
+		assert.NoError(t, printer.Diff(&b, "", ""))
+		assert.Empty(t, b.String())
+	})
+
+	t.Run("should write nothing if equal", func(t *testing.T) {
+		var b bytes.Buffer
+		assert.NoError(t, printer.Diff(&b, "a\nb\n", "a\nb\n"))
+		assert.Empty(t, b.String())
+	})
+
+	t.Run("should color added and removed lines when enabled", func(t *testing.T) {
+		withColors(t, true)
+
+		var b bytes.Buffer
+		assert.NoError(t, printer.Diff(&b, "a\n", "b\n"))
+		assert.Equal(t, "\x1b[36m@@ -1,1 +1,1 @@\x1b[0m\n\x1b[31m-a\x1b[0m\n\x1b[32m+b\x1b[0m\n", b.String())
+	})
+
+	t.Run("should write plain text to writer which is not a terminal", func(t *testing.T) {
+		// the profile of stdout is not used for other writers
+		withColors(t, true)
+		t.Cleanup(printer.UnsetColors())
+
+		var b bytes.Buffer
+		assert.NoError(t, printer.Diff(&b, "a\n", "b\n"))
+		assert.Equal(t, "@@ -1,1 +1,1 @@\n-a\n+b\n", b.String())
+	})
+
+	t.Run("should write plain text when colors are disabled", func(t *testing.T) {
+		withColors(t, false)
+
+		var b bytes.Buffer
+		assert.NoError(t, printer.Diff(&b, "a\n", "b\n"))
+		assert.Equal(t, "@@ -1,1 +1,1 @@\n-a\n+b\n", b.String())
+	})
+}
@@ -0,0 +1,25 @@
+package printer
+
+import "github.com/muesli/termenv"
+
+// SetColorProfile sets the color profile used by the helpers
+// and returns a func restoring the previous profile
+func SetColorProfile(p termenv.Profile) func() {
+	prev, prevSet := colorProfile, colorsSet
+	colorProfile, colorsSet = p, true
+	return func() { colorProfile, colorsSet = prev, prevSet }
+}
+
+// UnsetColors makes the output written to a given writer colored
+// if it is a terminal, as if colors were neither enabled nor
+// disabled, and returns a func restoring the previous setting
+func UnsetColors() func() {
+	prev := colorsSet
+	colorsSet = false
+	return func() { colorsSet = prev }
+}
+
+// ColorProfile returns the color profile used by the helpers
+func ColorProfile() termenv.Profile {
+	return colorProfile
+}
@@ -1,20 +1,39 @@
 package printer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 
+	"github.com/alecthomas/chroma/quick"
+	"github.com/odpf/salt/term"
 	"gopkg.in/yaml.v3"
 )
 
-// YAML prints the data as YAML.
-func YAML(data interface{}) error {
-	return Text(data, "yaml")
+// YAML writes the data as YAML to w, highlighted
+// if w is a terminal.
+func YAML(w io.Writer, data interface{}) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(data); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return highlight(w, buf.String(), "yaml")
 }
 
-// JSON prints the data as JSON.
-func JSON(data interface{}) error {
-	return Text(data, "json")
+// JSON writes the data as indented JSON to w,
+// highlighted if w is a terminal.
+func JSON(w io.Writer, data interface{}) error {
+	output, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return highlight(w, string(output)+"\n", "json")
 }
 
 // PrettyJSON prints the data as pretty JSON.
@@ -43,3 +62,11 @@ func Text(data interface{}, format string) (err error) {
 	fmt.Println(string(output))
 	return nil
 }
+
+func highlight(w io.Writer, source string, lexer string) error {
+	if !term.IsTerminal(w) || term.IsColorDisabled() {
+		_, err := io.WriteString(w, source)
+		return err
+	}
+	return quick.Highlight(w, source, lexer, "terminal256", "monokai")
+}
@@ -0,0 +1,48 @@
+package printer_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type resource struct {
+	Name   string            `json:"name" yaml:"name"`
+	Labels map[string]string `json:"labels" yaml:"labels"`
+}
+
+func TestJSON(t *testing.T) {
+	t.Run("should write indented json without highlighting", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printer.JSON(&out, resource{Name: "foo", Labels: map[string]string{"team": "bar"}})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "{\n  \"name\": \"foo\",\n  \"labels\": {\n    \"team\": \"bar\"\n  }\n}\n", out.String())
+		assert.True(t, json.Valid(out.Bytes()))
+	})
+
+	t.Run("should return error if data cannot be marshaled", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printer.JSON(&out, make(chan int))
+		assert.Error(t, err)
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestYAML(t *testing.T) {
+	t.Run("should write indented yaml without highlighting", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printer.YAML(&out, resource{Name: "foo", Labels: map[string]string{"team": "bar"}})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "name: foo\nlabels:\n  team: bar\n", out.String())
+
+		var r resource
+		assert.NoError(t, yaml.Unmarshal(out.Bytes(), &r))
+		assert.Equal(t, "bar", r.Labels["team"])
+	})
+}
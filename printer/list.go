@@ -0,0 +1,56 @@
+package printer
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/odpf/salt/term"
+)
+
+const (
+	descriptionListPadding  = 2
+	descriptionListMinWidth = 20
+)
+
+// DescriptionList writes the label and value pairs with the values
+// aligned, e.g. to show the details of a resource. Long values are
+// wrapped at the terminal width if w is a terminal.
+func DescriptionList(w io.Writer, pairs [][2]string) error {
+	return DescriptionListWithWrap(w, pairs, term.Width(w))
+}
+
+// DescriptionListWithWrap writes the label and value pairs with the
+// values aligned, wrapping the values so lines fit in the width.
+// Continuation lines are indented to the values, 0 disables wrapping.
+func DescriptionListWithWrap(w io.Writer, pairs [][2]string, wrap int) error {
+	labelWidth := 0
+	for _, p := range pairs {
+		if n := utf8.RuneCountInString(p[0]); n > labelWidth {
+			labelWidth = n
+		}
+	}
+	indent := labelWidth + descriptionListPadding
+
+	valueWidth := 0
+	if wrap-indent >= descriptionListMinWidth {
+		valueWidth = wrap - indent
+	}
+
+	var sb strings.Builder
+	for _, p := range pairs {
+		sb.WriteString(p[0])
+		for i, line := range wrapLines(p[1], valueWidth) {
+			if i == 0 {
+				sb.WriteString(strings.Repeat(" ", indent-utf8.RuneCountInString(p[0])))
+			} else {
+				sb.WriteString(strings.Repeat(" ", indent))
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
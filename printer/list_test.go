@@ -0,0 +1,68 @@
+package printer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescriptionList(t *testing.T) {
+	pairs := [][2]string{
+		{"Name", "quickstart"},
+		{"Format", "protobuf"},
+		{"Description", "Schemas of the quickstart guide used to try out the registry"},
+	}
+
+	t.Run("should align values to the longest label", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printer.DescriptionList(&out, pairs)
+		assert.NoError(t, err)
+
+		assert.Equal(t, ""+
+			"Name         quickstart\n"+
+			"Format       protobuf\n"+
+			"Description  Schemas of the quickstart guide used to try out the registry\n", out.String())
+	})
+
+	t.Run("should wrap long values to the value indent", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printer.DescriptionListWithWrap(&out, pairs, 44)
+		assert.NoError(t, err)
+
+		assert.Equal(t, ""+
+			"Name         quickstart\n"+
+			"Format       protobuf\n"+
+			"Description  Schemas of the quickstart guide\n"+
+			"             used to try out the registry\n", out.String())
+	})
+
+	t.Run("should indent lines of multiline values", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printer.DescriptionListWithWrap(&out, [][2]string{
+			{"Labels", "team=data\nenv=prod"},
+			{"ID", "1"},
+		}, 0)
+		assert.NoError(t, err)
+
+		assert.Equal(t, ""+
+			"Labels  team=data\n"+
+			"        env=prod\n"+
+			"ID      1\n", out.String())
+	})
+
+	t.Run("should not wrap if the width left for values is too small", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printer.DescriptionListWithWrap(&out, pairs, 20)
+		assert.NoError(t, err)
+
+		assert.Contains(t, out.String(), "Description  Schemas of the quickstart guide used to try out the registry\n")
+	})
+
+	t.Run("should write nothing for empty list", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, printer.DescriptionList(&out, nil))
+		assert.Empty(t, out.String())
+	})
+}
@@ -1,9 +1,13 @@
 package printer
 
 import (
+	"fmt"
+	"os"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
+	"github.com/muesli/termenv"
+	"github.com/odpf/salt/term"
 )
 
 type RenderOpts []glamour.TermRendererOption
@@ -39,9 +43,25 @@ func render(text string, opts RenderOpts) (string, error) {
 	return tr.Render(text)
 }
 
+// withDefaultStyle styles the markdown for the terminal background,
+// or without styling if colors are disabled, e.g. when stdout is not
+// a terminal. EnableColors forces the styling.
+func withDefaultStyle() glamour.TermRendererOption {
+	return glamour.WithStandardStyle(defaultTheme())
+}
+
+func defaultTheme() string {
+	if colorProfile == termenv.Ascii {
+		return "notty"
+	}
+	return "auto"
+}
+
+// Markdown renders the markdown for the terminal, without
+// styling if stdout is not a terminal.
 func Markdown(text string) (string, error) {
 	opts := RenderOpts{
-		glamour.WithAutoStyle(),
+		withDefaultStyle(),
 		glamour.WithEmoji(),
 		withoutIndentation(),
 		withoutWrap(),
@@ -50,9 +70,11 @@ func Markdown(text string) (string, error) {
 	return render(text, opts)
 }
 
+// MarkdownWithWrap renders the markdown wrapped at the given
+// width, without styling if stdout is not a terminal.
 func MarkdownWithWrap(text string, wrap int) (string, error) {
 	opts := RenderOpts{
-		glamour.WithAutoStyle(),
+		withDefaultStyle(),
 		glamour.WithEmoji(),
 		glamour.WithWordWrap(wrap),
 		withoutIndentation(),
@@ -60,3 +82,53 @@ func MarkdownWithWrap(text string, wrap int) (string, error) {
 
 	return render(text, opts)
 }
+
+type markdownOptions struct {
+	width int
+	theme string
+}
+
+type MarkdownOption func(*markdownOptions)
+
+// WithWidth sets the width the markdown is wrapped at,
+// 0 disables wrapping.
+func WithWidth(width int) MarkdownOption {
+	return func(o *markdownOptions) {
+		o.width = width
+	}
+}
+
+// WithTheme sets the theme of the rendered markdown,
+// one of dark, light or notty.
+func WithTheme(name string) MarkdownOption {
+	return func(o *markdownOptions) {
+		o.theme = name
+	}
+}
+
+// MarkdownWithOpts renders the markdown wrapped at the terminal
+// width using a theme matching the terminal background, or
+// without styling if stdout is not a terminal, unless
+// overridden by the options.
+func MarkdownWithOpts(text string, opts ...MarkdownOption) (string, error) {
+	o := &markdownOptions{
+		width: term.Width(os.Stdout),
+		theme: defaultTheme(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	switch o.theme {
+	case "auto", "dark", "light", "notty":
+	default:
+		return "", fmt.Errorf("unknown markdown theme: %s", o.theme)
+	}
+
+	return render(text, RenderOpts{
+		glamour.WithStandardStyle(o.theme),
+		glamour.WithEmoji(),
+		glamour.WithWordWrap(o.width),
+		withoutIndentation(),
+	})
+}
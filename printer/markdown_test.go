@@ -0,0 +1,82 @@
+package printer_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+const paragraph = "The quick brown fox jumps over the lazy dog while the cat watches from the window."
+
+func TestMarkdownWithOpts(t *testing.T) {
+	t.Run("should wrap at the given width", func(t *testing.T) {
+		out, err := printer.MarkdownWithOpts(paragraph, printer.WithWidth(20), printer.WithTheme("notty"))
+		assert.NoError(t, err)
+
+		lines := strings.Split(strings.Trim(out, "\n"), "\n")
+		assert.Greater(t, len(lines), 1)
+		for _, line := range lines {
+			assert.LessOrEqual(t, utf8.RuneCountInString(strings.TrimRight(line, " ")), 20)
+		}
+	})
+
+	t.Run("should not wrap if width is 0", func(t *testing.T) {
+		out, err := printer.MarkdownWithOpts(paragraph, printer.WithWidth(0), printer.WithTheme("notty"))
+		assert.NoError(t, err)
+
+		assert.Equal(t, paragraph, strings.TrimSpace(out))
+	})
+
+	t.Run("should not add ansi codes with notty theme", func(t *testing.T) {
+		out, err := printer.MarkdownWithOpts("# Title\n\nSome **bold** text", printer.WithTheme("notty"))
+		assert.NoError(t, err)
+
+		assert.NotContains(t, out, "\x1b[")
+		assert.Contains(t, out, "bold")
+	})
+
+	t.Run("should return error for unknown theme", func(t *testing.T) {
+		_, err := printer.MarkdownWithOpts(paragraph, printer.WithTheme("unknown"))
+		assert.EqualError(t, err, "unknown markdown theme: unknown")
+	})
+}
+
+func TestMarkdown(t *testing.T) {
+	const doc = "# Title\n\nSome **bold** text"
+
+	t.Run("should not style markdown if colors are disabled", func(t *testing.T) {
+		withColors(t, false)
+
+		out, err := printer.Markdown(doc)
+		assert.NoError(t, err)
+		assert.NotContains(t, out, "\x1b[")
+		assert.Contains(t, out, "bold")
+
+		out, err = printer.MarkdownWithWrap(doc, 20)
+		assert.NoError(t, err)
+		assert.NotContains(t, out, "\x1b[")
+
+		out, err = printer.MarkdownWithOpts(doc)
+		assert.NoError(t, err)
+		assert.NotContains(t, out, "\x1b[")
+	})
+
+	t.Run("should style markdown if colors are enabled", func(t *testing.T) {
+		withColors(t, true)
+
+		out, err := printer.Markdown(doc)
+		assert.NoError(t, err)
+		assert.Contains(t, out, "\x1b[")
+	})
+
+	t.Run("should style markdown if theme is set", func(t *testing.T) {
+		withColors(t, false)
+
+		out, err := printer.MarkdownWithOpts(doc, printer.WithTheme("dark"))
+		assert.NoError(t, err)
+		assert.Contains(t, out, "\x1b[")
+	})
+}
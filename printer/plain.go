@@ -0,0 +1,126 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// MarkdownPlain renders the markdown as plain text without any
+// styling or ANSI codes, e.g. for scripts and CI logs. Headings
+// are uppercased, list items are prefixed with dashes or their
+// number, code blocks are indented and links are followed by
+// their url. Raw HTML is dropped.
+func MarkdownPlain(s string) (string, error) {
+	source := []byte(strings.ReplaceAll(s, "\r\n", "\n"))
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	blocks := plainBlocks(doc, source)
+	if len(blocks) == 0 {
+		return "", nil
+	}
+	return strings.Join(blocks, "\n\n") + "\n", nil
+}
+
+func plainBlocks(parent ast.Node, source []byte) []string {
+	var blocks []string
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		if b := plainBlock(n, source); b != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+func plainBlock(n ast.Node, source []byte) string {
+	switch n := n.(type) {
+	case *ast.Heading:
+		return strings.ToUpper(plainInline(n, source))
+	case *ast.Paragraph, *ast.TextBlock:
+		return plainInline(n, source)
+	case *ast.List:
+		var items []string
+		number := n.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "- "
+			if n.IsOrdered() {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			body := strings.Join(plainBlocks(item, source), "\n")
+			items = append(items, marker+indentPlain(body, strings.Repeat(" ", len(marker))))
+		}
+		if n.IsTight {
+			return strings.Join(items, "\n")
+		}
+		return strings.Join(items, "\n\n")
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		var sb strings.Builder
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			segment := lines.At(i)
+			sb.Write(segment.Value(source))
+		}
+		return "    " + indentPlain(strings.TrimRight(sb.String(), "\n"), "    ")
+	case *ast.Blockquote:
+		return "  " + indentPlain(strings.Join(plainBlocks(n, source), "\n\n"), "  ")
+	case *ast.ThematicBreak:
+		return "---"
+	}
+	return ""
+}
+
+// plainInline returns the text of the inline children of the block
+func plainInline(block ast.Node, source []byte) string {
+	var sb strings.Builder
+	_ = ast.Walk(block, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch n := n.(type) {
+		case *ast.Text:
+			if entering {
+				sb.Write(n.Segment.Value(source))
+				if n.SoftLineBreak() || n.HardLineBreak() {
+					sb.WriteString("\n")
+				}
+			}
+		case *ast.String:
+			if entering {
+				sb.Write(n.Value)
+			}
+		case *ast.Link:
+			// links to anchors of the same document are dropped
+			dest := string(n.Destination)
+			if !entering && dest != "" && !strings.HasPrefix(dest, "#") && dest != string(n.Text(source)) {
+				sb.WriteString(" (" + dest + ")")
+			}
+		case *ast.AutoLink:
+			if entering {
+				sb.Write(n.URL(source))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Image:
+			if entering {
+				sb.Write(n.Text(source))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
+
+// indentPlain indents the lines of s after the first one,
+// leaving empty lines empty
+func indentPlain(s, indent string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,29 @@
+package printer_test
+
+import (
+	"testing"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownPlain(t *testing.T) {
+	t.Run("should render headings uppercased and lists with dashes", func(t *testing.T) {
+		out, err := printer.MarkdownPlain("# stencil reference\n\n## Contents\n\n- [stencil create](#stencil-create)\n  - nested **item**\n- `code` item\n\nSome *emphasized* text\nover two lines.\n")
+		assert.NoError(t, err)
+		assert.Equal(t, "STENCIL REFERENCE\n\nCONTENTS\n\n- stencil create\n  - nested item\n- code item\n\nSome emphasized text\nover two lines.\n", out)
+		assert.NotContains(t, out, "\x1b[")
+	})
+
+	t.Run("should render ordered lists, code blocks and links", func(t *testing.T) {
+		out, err := printer.MarkdownPlain("1. first\n2. second\n\n```sh\n$ stencil create\n  --format json\n```\n\nSee [the docs](https://odpf.io) or <https://github.com>.\n\n<a id=\"anchor\"></a>\n\n> quoted\n\n---\n")
+		assert.NoError(t, err)
+		assert.Equal(t, "1. first\n2. second\n\n    $ stencil create\n      --format json\n\nSee the docs (https://odpf.io) or https://github.com.\n\n  quoted\n\n---\n", out)
+	})
+
+	t.Run("should return empty string for empty markdown", func(t *testing.T) {
+		out, err := printer.MarkdownPlain("")
+		assert.NoError(t, err)
+		assert.Empty(t, out)
+	})
+}
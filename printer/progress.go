@@ -1,6 +1,10 @@
 package printer
 
 import (
+	"io"
+	"os"
+
+	"github.com/odpf/salt/term"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -13,3 +17,76 @@ func Progress(max int, description string) *progressbar.ProgressBar {
 	)
 	return bar
 }
+
+type progressOptions struct {
+	writer      io.Writer
+	description string
+	force       bool
+}
+
+type ProgressOption func(*progressOptions)
+
+// WithProgressWriter sets the writer the progress bar is rendered
+// to, defaults to stderr.
+func WithProgressWriter(w io.Writer) ProgressOption {
+	return func(o *progressOptions) {
+		o.writer = w
+	}
+}
+
+// WithProgressDescription sets the description shown before the bar.
+func WithProgressDescription(description string) ProgressOption {
+	return func(o *progressOptions) {
+		o.description = description
+	}
+}
+
+// WithProgressForced renders the progress bar even if the writer
+// is not a terminal.
+func WithProgressForced() ProgressOption {
+	return func(o *progressOptions) {
+		o.force = true
+	}
+}
+
+// ProgressBar renders the progress of an operation with its
+// percentage and rate. It implements io.Writer so it can
+// track the bytes copied by io.Copy.
+type ProgressBar struct {
+	bar *progressbar.ProgressBar
+}
+
+// NewProgress returns a progress bar for total steps or bytes.
+// It renders nothing if the writer is not a terminal.
+func NewProgress(total int64, opts ...ProgressOption) *ProgressBar {
+	o := &progressOptions{writer: os.Stderr}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	bar := progressbar.NewOptions64(
+		total,
+		progressbar.OptionSetWriter(o.writer),
+		progressbar.OptionSetDescription(o.description),
+		progressbar.OptionSetVisibility(o.force || term.IsTerminal(o.writer)),
+		progressbar.OptionShowCount(),
+		progressbar.OptionShowIts(),
+		progressbar.OptionSetWidth(30),
+	)
+	return &ProgressBar{bar: bar}
+}
+
+// Add advances the progress bar by n.
+func (p *ProgressBar) Add(n int) error {
+	return p.bar.Add(n)
+}
+
+// Write advances the progress bar by the length of b.
+func (p *ProgressBar) Write(b []byte) (int, error) {
+	return p.bar.Write(b)
+}
+
+// Finish fills the progress bar to completion.
+func (p *ProgressBar) Finish() error {
+	return p.bar.Finish()
+}
@@ -0,0 +1,40 @@
+package printer_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	t.Run("should render percentage as the bar advances", func(t *testing.T) {
+		var out bytes.Buffer
+		p := printer.NewProgress(10, printer.WithProgressWriter(&out), printer.WithProgressForced())
+
+		assert.NoError(t, p.Add(5))
+		assert.Contains(t, out.String(), " 50% ")
+		assert.Contains(t, out.String(), "(5/10, ")
+
+		out.Reset()
+		_, err := io.Copy(p, strings.NewReader("abc"))
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), " 80% ")
+
+		out.Reset()
+		assert.NoError(t, p.Finish())
+		assert.Contains(t, out.String(), " 100% ")
+	})
+
+	t.Run("should render nothing if writer is not a terminal", func(t *testing.T) {
+		var out bytes.Buffer
+		p := printer.NewProgress(10, printer.WithProgressWriter(&out))
+
+		assert.NoError(t, p.Add(5))
+		assert.NoError(t, p.Finish())
+		assert.Empty(t, out.String())
+	})
+}
@@ -1,34 +1,84 @@
 package printer
 
 import (
+	"io"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/odpf/salt/term"
 )
 
+const defaultSpinnerInterval = 120 * time.Millisecond
+
+type spinnerOptions struct {
+	writer   io.Writer
+	interval time.Duration
+	force    bool
+}
+
+type SpinnerOption func(*spinnerOptions)
+
+// WithSpinnerWriter sets the writer the spinner is rendered to,
+// defaults to stderr.
+func WithSpinnerWriter(w io.Writer) SpinnerOption {
+	return func(o *spinnerOptions) {
+		o.writer = w
+	}
+}
+
+// WithSpinnerInterval sets the delay between spinner frames.
+func WithSpinnerInterval(d time.Duration) SpinnerOption {
+	return func(o *spinnerOptions) {
+		o.interval = d
+	}
+}
+
+// WithSpinnerForced renders the spinner even if the writer
+// is not a terminal.
+func WithSpinnerForced() SpinnerOption {
+	return func(o *spinnerOptions) {
+		o.force = true
+	}
+}
+
 type Indicator struct {
 	spinner *spinner.Spinner
+	once    sync.Once
 }
 
+// Stop stops the spinner and clears it, it is safe to
+// call Stop multiple times and from multiple goroutines.
 func (s *Indicator) Stop() {
 	if s.spinner == nil {
 		return
 	}
-	s.spinner.Stop()
+	s.once.Do(s.spinner.Stop)
 }
 
-func Spin(label string) *Indicator {
-	set := spinner.CharSets[11]
-	if !term.IsTTY() {
+// Spin renders a spinner with the label until Stop is called.
+// It is a no-op if the writer is not a terminal.
+func Spin(label string, opts ...SpinnerOption) *Indicator {
+	o := &spinnerOptions{
+		writer:   os.Stderr,
+		interval: defaultSpinnerInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if !o.force && !term.IsTerminal(o.writer) {
 		return &Indicator{}
 	}
-	s := spinner.New(set, 120*time.Millisecond, spinner.WithColor("fgCyan"))
+
+	set := spinner.CharSets[11]
+	s := spinner.New(set, o.interval, spinner.WithColor("fgCyan"), spinner.WithWriter(o.writer))
 	if label != "" {
 		s.Prefix = label + " "
 	}
 
 	s.Start()
 
-	return &Indicator{s}
+	return &Indicator{spinner: s}
 }
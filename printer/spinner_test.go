@@ -0,0 +1,77 @@
+package printer_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSpin(t *testing.T) {
+	t.Run("should write frames until stopped", func(t *testing.T) {
+		var out syncBuffer
+		s := printer.Spin("fetching",
+			printer.WithSpinnerWriter(&out),
+			printer.WithSpinnerInterval(time.Millisecond),
+			printer.WithSpinnerForced(),
+		)
+		time.Sleep(20 * time.Millisecond)
+		s.Stop()
+
+		written := out.String()
+		assert.Contains(t, written, "fetching ⣾")
+		assert.Contains(t, written, "fetching ⣽")
+
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, written, out.String())
+	})
+
+	t.Run("should be safe to stop concurrently", func(t *testing.T) {
+		var out syncBuffer
+		s := printer.Spin("",
+			printer.WithSpinnerWriter(&out),
+			printer.WithSpinnerInterval(time.Millisecond),
+			printer.WithSpinnerForced(),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.Stop()
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("should write nothing if writer is not a terminal", func(t *testing.T) {
+		var out syncBuffer
+		s := printer.Spin("fetching", printer.WithSpinnerWriter(&out))
+		time.Sleep(10 * time.Millisecond)
+		s.Stop()
+		s.Stop()
+
+		assert.Empty(t, out.String())
+	})
+}
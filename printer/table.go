@@ -1,15 +1,18 @@
 package printer
 
 import (
+	"fmt"
 	"io"
-	"os"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/odpf/salt/term"
 	"github.com/olekukonko/tablewriter"
 )
 
 // Table writes a terminal-friendly table of the values to the target.
 func Table(target io.Writer, rows [][]string) {
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(target)
 	table.SetAutoWrapText(false)
 	table.SetAutoFormatHeaders(true)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
@@ -24,3 +27,164 @@ func Table(target io.Writer, rows [][]string) {
 	table.AppendBulk(rows)
 	table.Render()
 }
+
+const (
+	tableColumnPadding = 2
+	tableMinCellWidth  = 3
+)
+
+// TableBuilder builds a table with aligned columns.
+// Rows may have varying number of cells, missing
+// cells are rendered empty.
+type TableBuilder struct {
+	w        io.Writer
+	headers  []string
+	rows     [][]string
+	border   bool
+	maxWidth int
+}
+
+// NewTable returns a table builder writing to w. Cells are
+// truncated to fit the terminal width if w is a terminal.
+func NewTable(w io.Writer) *TableBuilder {
+	return &TableBuilder{
+		w:        w,
+		maxWidth: term.Width(w),
+	}
+}
+
+// Headers sets the header row of the table.
+func (t *TableBuilder) Headers(headers ...string) *TableBuilder {
+	t.headers = headers
+	return t
+}
+
+// AddRow appends a row to the table.
+func (t *TableBuilder) AddRow(cells ...string) *TableBuilder {
+	t.rows = append(t.rows, cells)
+	return t
+}
+
+// Border draws borders around the table and its cells.
+func (t *TableBuilder) Border(enabled bool) *TableBuilder {
+	t.border = enabled
+	return t
+}
+
+// MaxWidth sets the width the table is truncated to,
+// 0 disables truncation.
+func (t *TableBuilder) MaxWidth(width int) *TableBuilder {
+	t.maxWidth = width
+	return t
+}
+
+// Render writes the table, an empty table writes nothing.
+func (t *TableBuilder) Render() error {
+	rows := t.rows
+	if len(t.headers) > 0 {
+		rows = append([][]string{t.headers}, rows...)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	widths := t.columnWidths(rows)
+
+	var sb strings.Builder
+	separator := t.separator(widths)
+	if t.border {
+		sb.WriteString(separator)
+	}
+	for i, row := range rows {
+		t.writeRow(&sb, row, widths)
+		if t.border && i == 0 && len(t.headers) > 0 {
+			sb.WriteString(separator)
+		}
+	}
+	if t.border {
+		sb.WriteString(separator)
+	}
+
+	_, err := io.WriteString(t.w, sb.String())
+	return err
+}
+
+func (t *TableBuilder) columnWidths(rows [][]string) []int {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := utf8.RuneCountInString(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	if t.maxWidth <= 0 {
+		return widths
+	}
+
+	// shrink the widest column until the table fits
+	for t.tableWidth(widths) > t.maxWidth {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= tableMinCellWidth {
+			break
+		}
+		widths[widest]--
+	}
+	return widths
+}
+
+func (t *TableBuilder) tableWidth(widths []int) int {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	if t.border {
+		// "| " + cells joined by " | " + " |"
+		return total + 3*len(widths) + 1
+	}
+	return total + tableColumnPadding*(len(widths)-1)
+}
+
+func (t *TableBuilder) separator(widths []int) string {
+	var sb strings.Builder
+	for _, w := range widths {
+		sb.WriteString("+" + strings.Repeat("-", w+2))
+	}
+	sb.WriteString("+\n")
+	return sb.String()
+}
+
+func (t *TableBuilder) writeRow(sb *strings.Builder, row []string, widths []int) {
+	cells := make([]string, len(widths))
+	for i, w := range widths {
+		cell := ""
+		if i < len(row) {
+			cell = truncate(row[i], w)
+		}
+		cells[i] = fmt.Sprintf("%-*s", w, cell)
+	}
+
+	if t.border {
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		return
+	}
+	line := strings.Join(cells, strings.Repeat(" ", tableColumnPadding))
+	sb.WriteString(strings.TrimRight(line, " ") + "\n")
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
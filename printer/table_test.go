@@ -0,0 +1,86 @@
+package printer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	t.Run("should write rows to target", func(t *testing.T) {
+		var b bytes.Buffer
+		printer.Table(&b, [][]string{{"foo", "bar"}})
+
+		assert.Equal(t, "foo\tbar\t\n", b.String())
+	})
+}
+
+func TestTableBuilder(t *testing.T) {
+	t.Run("should align columns", func(t *testing.T) {
+		var b bytes.Buffer
+		err := printer.NewTable(&b).
+			Headers("NAME", "FORMAT", "VERSIONS").
+			AddRow("namespace-1", "protobuf", "2").
+			AddRow("ns-2", "avro", "10").
+			Render()
+
+		assert.NoError(t, err)
+		assert.Equal(t, ""+
+			"NAME         FORMAT    VERSIONS\n"+
+			"namespace-1  protobuf  2\n"+
+			"ns-2         avro      10\n", b.String())
+	})
+
+	t.Run("should render rows with varying column counts", func(t *testing.T) {
+		var b bytes.Buffer
+		err := printer.NewTable(&b).
+			Headers("NAME", "FORMAT").
+			AddRow("ns-1").
+			AddRow("ns-2", "avro", "extra").
+			Render()
+
+		assert.NoError(t, err)
+		assert.Equal(t, ""+
+			"NAME  FORMAT\n"+
+			"ns-1\n"+
+			"ns-2  avro    extra\n", b.String())
+	})
+
+	t.Run("should draw borders", func(t *testing.T) {
+		var b bytes.Buffer
+		err := printer.NewTable(&b).
+			Border(true).
+			Headers("NAME", "FORMAT").
+			AddRow("ns-1", "protobuf").
+			Render()
+
+		assert.NoError(t, err)
+		assert.Equal(t, ""+
+			"+------+----------+\n"+
+			"| NAME | FORMAT   |\n"+
+			"+------+----------+\n"+
+			"| ns-1 | protobuf |\n"+
+			"+------+----------+\n", b.String())
+	})
+
+	t.Run("should truncate widest column to max width", func(t *testing.T) {
+		var b bytes.Buffer
+		err := printer.NewTable(&b).
+			MaxWidth(20).
+			AddRow("ns-1", "a very long description").
+			Render()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ns-1  a very long d…\n", b.String())
+	})
+
+	t.Run("should write nothing for empty table", func(t *testing.T) {
+		var b bytes.Buffer
+		err := printer.NewTable(&b).Render()
+
+		assert.NoError(t, err)
+		assert.Empty(t, b.String())
+	})
+}
@@ -0,0 +1,61 @@
+package printer
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/odpf/salt/term"
+)
+
+var ansiRE = regexp.MustCompile("\x1b\\[[0-9;]*[a-zA-Z]")
+
+// Wrap wraps the words of the text so lines fit in the width, the
+// existing line breaks and blank lines between paragraphs are kept.
+// ANSI escape sequences, e.g. colors, do not count towards the width.
+// Words longer than the width are not broken, 0 disables wrapping.
+func Wrap(s string, width int) string {
+	return strings.Join(wrapLines(s, width), "\n")
+}
+
+// WrapToTerminal wraps the text at the width of the terminal,
+// the text is not wrapped if stdout is not a terminal.
+func WrapToTerminal(s string) string {
+	return Wrap(s, term.Width(os.Stdout))
+}
+
+// wrapLines splits the text in lines, wrapping words so lines fit in
+// the width, words longer than the width are not broken
+func wrapLines(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if width <= 0 || visibleWidth(line) <= width {
+			lines = append(lines, line)
+			continue
+		}
+
+		current, currentWidth := "", 0
+		for _, word := range strings.Fields(line) {
+			wordWidth := visibleWidth(word)
+			switch {
+			case current == "":
+				current, currentWidth = word, wordWidth
+			case currentWidth+1+wordWidth <= width:
+				current += " " + word
+				currentWidth += 1 + wordWidth
+			default:
+				lines = append(lines, current)
+				current, currentWidth = word, wordWidth
+			}
+		}
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// visibleWidth returns the number of characters of the text
+// shown in the terminal, without the ANSI escape sequences
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiRE.ReplaceAllString(s, ""))
+}
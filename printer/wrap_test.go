@@ -0,0 +1,40 @@
+package printer_test
+
+import (
+	"testing"
+
+	"github.com/odpf/salt/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	t.Run("should wrap words at the width", func(t *testing.T) {
+		s := printer.Wrap("the quick brown fox jumps over the lazy dog", 15)
+
+		assert.Equal(t, "the quick brown\nfox jumps over\nthe lazy dog", s)
+	})
+
+	t.Run("should keep paragraphs separated by blank lines", func(t *testing.T) {
+		s := printer.Wrap("the quick brown fox jumps\n\nover the lazy dog\nand runs away", 10)
+
+		assert.Equal(t, "the quick\nbrown fox\njumps\n\nover the\nlazy dog\nand runs\naway", s)
+	})
+
+	t.Run("should not break long words", func(t *testing.T) {
+		s := printer.Wrap("see https://odpf.github.io/docs/installation for details", 20)
+
+		assert.Equal(t, "see\nhttps://odpf.github.io/docs/installation\nfor details", s)
+	})
+
+	t.Run("should not count escape sequences in width", func(t *testing.T) {
+		s := printer.Wrap("the \x1b[1mquick\x1b[0m brown \x1b[31mfox\x1b[0m jumps", 15)
+
+		assert.Equal(t, "the \x1b[1mquick\x1b[0m brown\n\x1b[31mfox\x1b[0m jumps", s)
+	})
+
+	t.Run("should not wrap with zero width", func(t *testing.T) {
+		s := printer.Wrap("the quick brown fox jumps over the lazy dog", 0)
+
+		assert.Equal(t, "the quick brown fox jumps over the lazy dog", s)
+	})
+}
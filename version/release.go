@@ -38,7 +38,7 @@ func ReleaseInfo(releaseURL string) (*Info, error) {
 		return nil, errors.Wrapf(err, "failed to reach releaseURL: %s", releaseURL)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Wrapf(err, "failed to reach releaseURL: %s, returned: %d", releaseURL, resp.StatusCode)
+		return nil, errors.Errorf("failed to reach releaseURL: %s, returned: %d", releaseURL, resp.StatusCode)
 	}
 	if resp.Body != nil {
 		defer resp.Body.Close()